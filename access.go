@@ -0,0 +1,87 @@
+package jsonapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// AttributeAccess declares how clients may use an attribute of a resource type.
+type AttributeAccess int
+
+const (
+	// ReadWrite attributes may be read and written by clients, which is the default for undeclared attributes.
+	ReadWrite AttributeAccess = iota
+
+	// ReadOnly attributes are returned to clients but must not be sent by them, e.g. timestamps set by the server.
+	ReadOnly
+
+	// CreateOnly attributes may be sent by clients when creating a resource, but not when updating it.
+	CreateOnly
+
+	// WriteOnly attributes may be sent by clients but are never returned to them, e.g. passwords.
+	WriteOnly
+)
+
+// String returns the name of the access level.
+func (a AttributeAccess) String() string {
+	switch a {
+	case ReadWrite:
+		return "read-write"
+	case ReadOnly:
+		return "read-only"
+	case CreateOnly:
+		return "create-only"
+	case WriteOnly:
+		return "write-only"
+	default:
+		return fmt.Sprintf("AttributeAccess(%d)", int(a))
+	}
+}
+
+// checkAttributeAccess returns a 403 Forbidden error for each attribute in as which clients must not send
+// for the given intent, as declared by access.
+func checkAttributeAccess(access map[string]AttributeAccess, as Attributes, intent intent) error {
+	keys := make([]string, 0, len(as))
+	for key := range as {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs Errors
+	for _, key := range keys {
+		a := access[key]
+		if a == ReadOnly || (a == CreateOnly && intent == intentUpdate) {
+			errs = append(errs, Error{
+				Status: fmt.Sprint(http.StatusForbidden),
+				Title:  "Forbidden attribute",
+				Detail: fmt.Sprintf("attribute %q is %s and cannot be set when %s a resource", key, a, intent),
+				Source: &ErrorSource{Pointer: jsonPointer("data", "attributes", key)},
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// withoutWriteOnly returns a copy of as without the attributes declared as write-only by access, or as itself
+// if there are none.
+func withoutWriteOnly(access map[string]AttributeAccess, as Attributes) Attributes {
+	filtered := as
+	for key := range as {
+		if access[key] != WriteOnly {
+			continue
+		}
+		if len(filtered) == len(as) {
+			filtered = make(Attributes, len(as))
+			for k, v := range as {
+				filtered[k] = v
+			}
+		}
+		delete(filtered, key)
+	}
+	return filtered
+}
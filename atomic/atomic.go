@@ -0,0 +1,113 @@
+// Package atomic implements the JSON API Atomic Operations extension, which allows a client to perform several
+// operations, such as creating, updating and removing resources or relationships, within a single request that
+// either succeeds or fails as a whole.
+//
+// The request and response documents are represented by Document, and the operations are executed by a
+// Processor, which validates them, resolves local identifiers ("lid") across operations and hands each of them
+// to a Dispatcher within a transaction.
+//
+// https://jsonapi.org/ext/atomic/
+package atomic // import "github.com/smotes/jsonapi/atomic"
+
+import (
+	"encoding/json"
+
+	"github.com/smotes/jsonapi"
+)
+
+// ExtensionURI is the URI identifying the Atomic Operations extension.
+const ExtensionURI string = "https://jsonapi.org/ext/atomic"
+
+// Extension describes the Atomic Operations extension, for registration with a jsonapi.MediaTypeRegistry.
+var Extension = jsonapi.Extension{URI: ExtensionURI, Namespace: "atomic"}
+
+// MediaType is the media type of requests and responses using the Atomic Operations extension.
+const MediaType string = jsonapi.MediaType + `;ext="` + ExtensionURI + `"`
+
+// Document represents the top-level document of an atomic request, containing operations,
+// or of an atomic response, containing their results.
+//
+// https://jsonapi.org/ext/atomic/#document-structure
+type Document struct {
+	Operations []Operation    `json:"atomic:operations,omitempty"`
+	Results    []Result       `json:"atomic:results,omitempty"`
+	Errors     jsonapi.Errors `json:"errors,omitempty"`
+	Meta       jsonapi.Meta   `json:"meta,omitempty"`
+	Info       *jsonapi.Info  `json:"jsonapi,omitempty"`
+	Links      jsonapi.Links  `json:"links,omitempty"`
+}
+
+// Code represents the code of an operation, i.e. the value of its "op" member.
+type Code string
+
+// The operation codes defined by the extension.
+const (
+	Add    Code = "add"
+	Update Code = "update"
+	Remove Code = "remove"
+)
+
+// Operation represents an operation object, a member of the "atomic:operations" array.
+//
+// The target of the operation is given by either Ref or Href, or may be omitted when adding a resource or
+// updating one given in full by Data. An operation adding a resource may also give the resource collection as
+// Href, e.g. "/articles", whose type must then match the type of the resource. Once processed by a Processor,
+// Ref always holds the resolved target if there is one, with all local identifiers replaced by server-assigned IDs.
+//
+// https://jsonapi.org/ext/atomic/#operation-objects
+type Operation struct {
+	Op   Code            `json:"op"`
+	Ref  *Ref            `json:"ref,omitempty"`
+	Href string          `json:"href,omitempty"`
+	Data json.RawMessage `json:"data,omitempty"`
+	Meta jsonapi.Meta    `json:"meta,omitempty"`
+}
+
+// Ref represents the target of an operation: a resource, identified by its type and either its ID or local
+// identifier, or one of its relationships.
+//
+// https://jsonapi.org/ext/atomic/#operation-objects
+type Ref struct {
+	Type         string `json:"type"`
+	ID           string `json:"id,omitempty"`
+	Lid          string `json:"lid,omitempty"`
+	Relationship string `json:"relationship,omitempty"`
+}
+
+// Result represents a result object, a member of the "atomic:results" array. Results are empty for operations
+// which return no data.
+//
+// https://jsonapi.org/ext/atomic/#result-objects
+type Result struct {
+	Data json.RawMessage `json:"data,omitempty"`
+	Meta jsonapi.Meta    `json:"meta,omitempty"`
+}
+
+// Resource decodes the operation's data as a resource object, as used when adding or updating resources.
+func (op *Operation) Resource() (*jsonapi.Resource, error) {
+	r := &jsonapi.Resource{}
+	if err := json.Unmarshal(op.Data, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Relationship returns the operation's data as the data of a relationship, as used by operations targeting
+// relationships, so it can be decoded with its ToOne and ToMany methods.
+func (op *Operation) Relationship() *jsonapi.Relationship {
+	return &jsonapi.Relationship{Data: op.Data}
+}
+
+// IsRelationship returns true if the operation targets a relationship rather than a resource.
+func (op *Operation) IsRelationship() bool {
+	return op.Ref != nil && len(op.Ref.Relationship) > 0
+}
+
+// NewResult returns a result holding the resource r as its data.
+func NewResult(r *jsonapi.Resource) (Result, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Data: b}, nil
+}
@@ -0,0 +1,385 @@
+package atomic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/smotes/jsonapi"
+)
+
+// Dispatcher executes the operations of an atomic request on behalf of a Processor.
+//
+// Begin is called once before the first operation, and returns the context passed to all further calls,
+// typically carrying the transaction. Commit is called after all operations succeeded, and Rollback as soon as
+// any of them failed, after which no further operations are dispatched.
+type Dispatcher interface {
+	Begin(ctx context.Context) (context.Context, error)
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+
+	// Dispatch executes a single operation and returns its result, which must hold the created resource for
+	// operations adding a resource, so that its local identifier can be resolved by later operations.
+	// The operation's Ref holds its resolved target, if any, and all local identifiers in its Ref and Data
+	// are replaced by server-assigned IDs.
+	//
+	// Errors of type *jsonapi.Error or jsonapi.Errors are returned to the client, with a source pointer
+	// to the operation added if they have none.
+	Dispatch(ctx context.Context, op *Operation) (*Result, error)
+}
+
+// Processor validates and executes the operations of atomic requests using its Dispatcher.
+type Processor struct {
+	Dispatcher Dispatcher
+
+	// BasePath is stripped from the path of an operation's "href" before it is parsed into a Ref,
+	// e.g. "/api" for "/api/articles/1".
+	BasePath string
+
+	// MaxOperations limits the number of operations per request, if greater than zero.
+	MaxOperations int
+}
+
+// Process validates and executes the operations of the request document in order, within a single transaction,
+// and returns the response document holding their results.
+//
+// If the request document is invalid, or any operation fails, the transaction is rolled back and an error is
+// returned: Errors describing the problem with source pointers such as "/atomic:operations/1/ref", or any other
+// error returned by the Dispatcher as is.
+func (p *Processor) Process(ctx context.Context, doc *Document) (*Document, error) {
+	if len(doc.Operations) == 0 {
+		return nil, jsonapi.Errors{*badRequest("Missing operations",
+			"request document must contain at least one operation", "/atomic:operations")}
+	}
+	if p.MaxOperations > 0 && len(doc.Operations) > p.MaxOperations {
+		return nil, jsonapi.Errors{{
+			Status: fmt.Sprint(http.StatusRequestEntityTooLarge),
+			Title:  "Too many operations",
+			Detail: fmt.Sprintf("request document must contain at most %d operations", p.MaxOperations),
+			Source: &jsonapi.ErrorSource{Pointer: "/atomic:operations"},
+		}}
+	}
+
+	// validate all operations up front, so that invalid requests never start a transaction
+	ops := make([]Operation, len(doc.Operations))
+	var errs jsonapi.Errors
+	lids := make(map[string]bool)
+	for i := range doc.Operations {
+		op, err := p.prepare(&doc.Operations[i], lids)
+		if err != nil {
+			errs = append(errs, *withOperationPointer(err, i))
+			continue
+		}
+		ops[i] = *op
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	ctx, err := p.Dispatcher.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := p.dispatch(ctx, ops)
+	if err != nil {
+		if rerr := p.Dispatcher.Rollback(ctx); rerr != nil {
+			return nil, rerr
+		}
+		return nil, err
+	}
+	if err := p.Dispatcher.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &Document{Results: results}, nil
+}
+
+func (p *Processor) dispatch(ctx context.Context, ops []Operation) ([]Result, error) {
+	ls := &jsonapi.LocalIDs{}
+	results := make([]Result, len(ops))
+
+	for i := range ops {
+		op := &ops[i]
+		lid, err := resolve(ls, op)
+		if err != nil {
+			return nil, jsonapi.Errors{*withOperationPointer(err, i)}
+		}
+
+		result, err := p.Dispatcher.Dispatch(ctx, op)
+		if err != nil {
+			switch e := err.(type) {
+			case *jsonapi.Error:
+				return nil, jsonapi.Errors{*withOperationPointer(e, i)}
+			case jsonapi.Errors:
+				rebased := make(jsonapi.Errors, len(e))
+				for j := range e {
+					rebased[j] = *withOperationPointer(&e[j], i)
+				}
+				return nil, rebased
+			default:
+				return nil, err
+			}
+		}
+		if result != nil {
+			results[i] = *result
+		}
+
+		// record the server-assigned ID of resources added with a local identifier
+		if len(lid) > 0 {
+			r := jsonapi.Resource{}
+			if result != nil && len(result.Data) > 0 {
+				if err := json.Unmarshal(result.Data, &r); err != nil {
+					return nil, err
+				}
+			}
+			if len(r.ID) == 0 {
+				return nil, fmt.Errorf("%s: result of operation %d must hold the added resource", packageName, i)
+			}
+			if err := ls.Set(r.Type, lid, r.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// prepare validates the operation and returns a copy of it with its target resolved into Ref.
+// Local identifiers added by the operation are recorded in lids, to detect duplicates.
+func (p *Processor) prepare(op *Operation, lids map[string]bool) (*Operation, error) {
+	prepared := *op
+
+	// collection holds the type of the resource collection targeted by the operation's href, if any
+	collection := ""
+	if len(op.Href) > 0 {
+		if op.Ref != nil {
+			return nil, badRequest("Invalid operation", `operation must not contain both "ref" and "href"`, "/href")
+		}
+		ref, err := p.parseHref(op.Href)
+		if err != nil {
+			return nil, err
+		}
+		if len(ref.ID) > 0 {
+			prepared.Ref = ref
+		} else if op.Op == Add {
+			collection = ref.Type
+		} else {
+			return nil, badRequest("Invalid href",
+				fmt.Sprintf("href %q must identify a resource or relationship", op.Href), "/href")
+		}
+	} else if op.Ref != nil {
+		ref := *op.Ref
+		prepared.Ref = &ref
+	}
+
+	if ref := prepared.Ref; ref != nil {
+		switch {
+		case len(ref.Type) == 0:
+			return nil, badRequest("Invalid ref", `ref must contain "type"`, "/ref")
+		case len(ref.ID) > 0 && len(ref.Lid) > 0:
+			return nil, badRequest("Invalid ref", `ref must not contain both "id" and "lid"`, "/ref")
+		case len(ref.ID) == 0 && len(ref.Lid) == 0:
+			return nil, badRequest("Invalid ref", `ref must contain either "id" or "lid"`, "/ref")
+		}
+	}
+
+	kind := dataKind(op.Data)
+	switch op.Op {
+	case Add:
+		if prepared.IsRelationship() {
+			if kind != '[' {
+				return nil, badRequest("Invalid data", "data must be an array of resource identifiers", "/data")
+			}
+			return &prepared, nil
+		}
+		if prepared.Ref != nil {
+			return nil, badRequest("Invalid ref", "ref of an operation adding a resource must target a relationship", "/ref")
+		}
+		if kind != '{' {
+			return nil, badRequest("Invalid data", "data must be a resource object", "/data")
+		}
+		r, err := prepared.Resource()
+		if err != nil {
+			return nil, badRequest("Invalid data", err.Error(), "/data")
+		}
+		if len(collection) > 0 && r.Type != collection {
+			return nil, &jsonapi.Error{
+				Status: fmt.Sprint(http.StatusConflict),
+				Title:  "Conflicting data",
+				Detail: fmt.Sprintf("resource type %q does not match the collection %q of the operation's href",
+					r.Type, collection),
+				Source: &jsonapi.ErrorSource{Pointer: "/data/type"},
+			}
+		}
+		if len(r.Lid) > 0 {
+			if lids[r.Lid] {
+				return nil, badRequest("Duplicate lid",
+					fmt.Sprintf("lid %q is already used by an earlier operation", r.Lid), "/data/lid")
+			}
+			lids[r.Lid] = true
+		}
+
+	case Update:
+		if prepared.IsRelationship() {
+			if kind != '[' && kind != '{' && kind != 'n' {
+				return nil, badRequest("Invalid data",
+					"data must be a resource identifier, null or an array of resource identifiers", "/data")
+			}
+			return &prepared, nil
+		}
+		if kind != '{' {
+			return nil, badRequest("Invalid data", "data must be a resource object", "/data")
+		}
+		r, err := prepared.Resource()
+		if err != nil {
+			return nil, badRequest("Invalid data", err.Error(), "/data")
+		}
+		if len(r.ID) == 0 && len(r.Lid) == 0 {
+			return nil, badRequest("Invalid data", `resource must contain either "id" or "lid"`, "/data")
+		}
+		if ref := prepared.Ref; ref != nil && (ref.Type != r.Type || ref.ID != r.ID || ref.Lid != r.Lid) {
+			return nil, &jsonapi.Error{
+				Status: fmt.Sprint(http.StatusConflict),
+				Title:  "Conflicting data",
+				Detail: "resource in data does not match the operation's ref",
+				Source: &jsonapi.ErrorSource{Pointer: "/data"},
+			}
+		}
+		if prepared.Ref == nil {
+			prepared.Ref = &Ref{Type: r.Type, ID: r.ID, Lid: r.Lid}
+		}
+
+	case Remove:
+		if prepared.Ref == nil {
+			return nil, badRequest("Invalid operation", `operation removing data must contain "ref" or "href"`, "")
+		}
+		if prepared.IsRelationship() && kind != '[' {
+			return nil, badRequest("Invalid data", "data must be an array of resource identifiers", "/data")
+		}
+
+	default:
+		return nil, badRequest("Invalid operation", fmt.Sprintf("unknown operation code %q", op.Op), "/op")
+	}
+
+	return &prepared, nil
+}
+
+// resolve replaces all local identifiers in the operation's Ref and Data with server-assigned IDs, and returns
+// the local identifier of the resource added by the operation, if any.
+func resolve(ls *jsonapi.LocalIDs, op *Operation) (string, error) {
+	if ref := op.Ref; ref != nil && len(ref.Lid) > 0 {
+		id, ok := ls.Lookup(ref.Type, ref.Lid)
+		if !ok {
+			return "", badRequest("Unknown lid",
+				fmt.Sprintf("lid %q does not identify a resource of type %q", ref.Lid, ref.Type), "/ref/lid")
+		}
+		ref.ID, ref.Lid = id, ""
+	}
+
+	if op.IsRelationship() {
+		rel := op.Relationship()
+		if err := ls.ResolveRelationship(rel); err != nil {
+			return "", err
+		}
+		op.Data = rel.Data
+		return "", nil
+	}
+	if len(op.Data) == 0 || dataKind(op.Data) != '{' {
+		return "", nil
+	}
+
+	r, err := op.Resource()
+	if err != nil {
+		return "", err
+	}
+	lid := ""
+	if op.Op == Add {
+		lid = r.Lid
+	} else if len(r.ID) == 0 && len(r.Lid) > 0 {
+		r.ID = op.Ref.ID
+	}
+	if err := ls.ResolveRelationships(r.Relationships); err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	op.Data = b
+	return lid, nil
+}
+
+// parseHref parses hrefs of the forms "/{type}", "/{type}/{id}" and "/{type}/{id}/relationships/{relationship}".
+// The Ref of a resource collection only holds its type.
+func (p *Processor) parseHref(href string) (*Ref, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return nil, badRequest("Invalid href", err.Error(), "/href")
+	}
+
+	path := strings.TrimPrefix(u.Path, strings.TrimSuffix(p.BasePath, "/"))
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(segments) == 1 && len(segments[0]) > 0:
+		return &Ref{Type: segments[0]}, nil
+	case len(segments) == 2:
+		return &Ref{Type: segments[0], ID: segments[1]}, nil
+	case len(segments) == 4 && segments[2] == "relationships":
+		return &Ref{Type: segments[0], ID: segments[1], Relationship: segments[3]}, nil
+	default:
+		return nil, badRequest("Invalid href",
+			fmt.Sprintf("href %q does not identify a resource collection, resource or relationship", href), "/href")
+	}
+}
+
+// helpers
+
+const packageName string = "github.com/smotes/jsonapi/atomic"
+
+func badRequest(title, detail, pointer string) *jsonapi.Error {
+	return &jsonapi.Error{
+		Status: fmt.Sprint(http.StatusBadRequest),
+		Title:  title,
+		Detail: detail,
+		Source: &jsonapi.ErrorSource{Pointer: pointer},
+	}
+}
+
+// withOperationPointer returns a copy of err with its source pointer, relative to the operation, prefixed with
+// the pointer to the operation at index i, or converts err into a 400 Bad Request error pointing at the operation.
+// Errors are copied rather than changed, as the Dispatcher may return shared ones.
+func withOperationPointer(err error, i int) *jsonapi.Error {
+	prefix := "/atomic:operations/" + strconv.Itoa(i)
+
+	e, ok := err.(*jsonapi.Error)
+	if !ok {
+		e = badRequest("Invalid operation", err.Error(), "")
+	}
+	rebased := *e
+	source := jsonapi.ErrorSource{}
+	if e.Source != nil {
+		source = *e.Source
+	}
+	if len(source.Parameter) == 0 && len(source.Header) == 0 {
+		source.Pointer = prefix + source.Pointer
+	}
+	rebased.Source = &source
+	return &rebased
+}
+
+// dataKind returns the first non-whitespace byte of the raw JSON value b, telling its kind, or 0 if b is empty.
+func dataKind(b []byte) byte {
+	for _, c := range b {
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return c
+	}
+	return 0
+}
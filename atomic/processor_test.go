@@ -0,0 +1,286 @@
+package atomic_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+	"github.com/smotes/jsonapi/atomic"
+)
+
+// testDispatcher records the operations it is given and assigns sequential IDs to added resources.
+type testDispatcher struct {
+	ops        []atomic.Operation
+	nextID     int
+	fail       error
+	committed  bool
+	rolledBack bool
+}
+
+type txKey struct{}
+
+func (d *testDispatcher) Begin(ctx context.Context) (context.Context, error) {
+	return context.WithValue(ctx, txKey{}, true), nil
+}
+
+func (d *testDispatcher) Commit(ctx context.Context) error {
+	d.committed = true
+	return nil
+}
+
+func (d *testDispatcher) Rollback(ctx context.Context) error {
+	d.rolledBack = true
+	return nil
+}
+
+func (d *testDispatcher) Dispatch(ctx context.Context, op *atomic.Operation) (*atomic.Result, error) {
+	if ctx.Value(txKey{}) == nil {
+		return nil, errors.New("operation dispatched outside of transaction")
+	}
+	d.ops = append(d.ops, *op)
+	if d.fail != nil && len(d.ops) == 2 {
+		return nil, d.fail
+	}
+
+	if op.Op != atomic.Add || op.IsRelationship() {
+		return nil, nil
+	}
+	r, err := op.Resource()
+	if err != nil {
+		return nil, err
+	}
+	d.nextID++
+	r.ID = strconv.Itoa(d.nextID)
+	r.Lid = ""
+	result, err := atomic.NewResult(r)
+	return &result, err
+}
+
+func decodeDocument(t *testing.T, s string) *atomic.Document {
+	doc := &atomic.Document{}
+	if err := json.Unmarshal([]byte(s), doc); err != nil {
+		t.Fatalf("unexpected error when unmarshaling atomic document: %+v", err)
+	}
+	return doc
+}
+
+func TestProcessor_Process(t *testing.T) {
+	doc := decodeDocument(t, `
+	{
+		"atomic:operations": [
+			{"op": "add", "data": {"type": "people", "lid": "p1", "attributes": {"name": "foo"}}},
+			{"op": "add", "href": "/api/articles", "data": {
+				"type": "articles", "lid": "a1",
+				"relationships": {"author": {"data": {"type": "people", "lid": "p1"}}}
+			}},
+			{"op": "update", "ref": {"type": "articles", "lid": "a1", "relationship": "author"},
+				"data": {"type": "people", "lid": "p1"}},
+			{"op": "add", "href": "/api/articles/1/relationships/tags", "data": [{"type": "tags", "id": "7"}]},
+			{"op": "update", "data": {"type": "people", "lid": "p1", "attributes": {"name": "bar"}}},
+			{"op": "remove", "ref": {"type": "articles", "lid": "a1"}}
+		]
+	}`)
+
+	d := &testDispatcher{}
+	p := atomic.Processor{Dispatcher: d, BasePath: "/api"}
+	res, err := p.Process(context.Background(), doc)
+	if err != nil {
+		t.Fatalf("unexpected error from Processor.Process: %+v", err)
+	}
+	if !d.committed || d.rolledBack {
+		t.Error("Processor.Process should commit the transaction when all operations succeed")
+	}
+	if len(res.Results) != len(doc.Operations) {
+		t.Fatalf("unexpected number of results, expected: %d, actual: %d", len(doc.Operations), len(res.Results))
+	}
+
+	r := jsonapi.Resource{}
+	if err := json.Unmarshal(res.Results[1].Data, &r); err != nil || r.ID != "2" {
+		t.Errorf("unexpected result of operation adding a resource: %s", res.Results[1].Data)
+	}
+	if len(res.Results[2].Data) != 0 {
+		t.Errorf("expected empty result for operation without data: %s", res.Results[2].Data)
+	}
+
+	// local identifiers must be resolved across operations
+	article, _ := d.ops[1].Resource()
+	rel, _ := article.Relationships.Get("author")
+	if ri, _ := rel.ToOne(); ri == nil || ri.ID != "1" {
+		t.Errorf("expected lid in relationship data to be resolved, actual: %s", rel.Data)
+	}
+	if ref := d.ops[1].Ref; ref != nil {
+		t.Errorf("expected href of resource collection not to be parsed into ref, actual: %+v", ref)
+	}
+	if ref := d.ops[2].Ref; ref.ID != "2" || len(ref.Lid) > 0 || ref.Relationship != "author" {
+		t.Errorf("expected lid in ref to be resolved, actual: %+v", ref)
+	}
+	if ri, _ := d.ops[2].Relationship().ToOne(); ri == nil || ri.ID != "1" {
+		t.Errorf("expected lid in relationship operation data to be resolved, actual: %s", d.ops[2].Data)
+	}
+	if ref := d.ops[3].Ref; ref == nil || ref.Type != "articles" || ref.ID != "1" || ref.Relationship != "tags" {
+		t.Errorf("expected href to be parsed into ref, actual: %+v", ref)
+	}
+	if ref := d.ops[4].Ref; ref == nil || ref.Type != "people" || ref.ID != "1" {
+		t.Errorf("expected target of update to be resolved from data, actual: %+v", ref)
+	}
+	if person, _ := d.ops[4].Resource(); person.ID != "1" {
+		t.Errorf("expected lid of updated resource to be resolved, actual: %+v", person)
+	}
+	if ref := d.ops[5].Ref; ref.ID != "2" {
+		t.Errorf("expected lid in ref of remove operation to be resolved, actual: %+v", ref)
+	}
+}
+
+func TestProcessor_Process_WhenInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		pointers []string
+	}{
+		{"no operations", `{"atomic:operations": []}`, []string{"/atomic:operations"}},
+		{"unknown op", `{"atomic:operations": [{"op": "replace"}]}`, []string{"/atomic:operations/0/op"}},
+		{"ref and href", `{"atomic:operations": [
+			{"op": "remove", "ref": {"type": "articles", "id": "1"}, "href": "/articles/1"}
+		]}`, []string{"/atomic:operations/0/href"}},
+		{"ref without id", `{"atomic:operations": [{"op": "remove", "ref": {"type": "articles"}}]}`,
+			[]string{"/atomic:operations/0/ref"}},
+		{"remove without target", `{"atomic:operations": [{"op": "remove"}]}`, []string{"/atomic:operations/0"}},
+		{"add with invalid data", `{"atomic:operations": [
+			{"op": "add", "data": {"type": "articles"}},
+			{"op": "add", "data": [{"type": "articles"}]}
+		]}`, []string{"/atomic:operations/1/data"}},
+		{"duplicate lid", `{"atomic:operations": [
+			{"op": "add", "data": {"type": "articles", "lid": "a1"}},
+			{"op": "add", "data": {"type": "articles", "lid": "a1"}}
+		]}`, []string{"/atomic:operations/1/data/lid"}},
+		{"conflicting collection", `{"atomic:operations": [
+			{"op": "add", "href": "/people", "data": {"type": "articles"}}
+		]}`, []string{"/atomic:operations/0/data/type"}},
+		{"remove from collection", `{"atomic:operations": [{"op": "remove", "href": "/articles"}]}`,
+			[]string{"/atomic:operations/0/href"}},
+		{"conflicting update", `{"atomic:operations": [
+			{"op": "update", "ref": {"type": "articles", "id": "1"}, "data": {"type": "articles", "id": "2"}}
+		]}`, []string{"/atomic:operations/0/data"}},
+	}
+
+	for _, test := range tests {
+		d := &testDispatcher{}
+		p := atomic.Processor{Dispatcher: d}
+		_, err := p.Process(context.Background(), decodeDocument(t, test.doc))
+
+		var errs jsonapi.Errors
+		if !errors.As(err, &errs) || len(errs) != len(test.pointers) {
+			t.Errorf("%s: expected Processor.Process to return %d errors, actual: %+v", test.name, len(test.pointers), err)
+			continue
+		}
+		for i, e := range errs {
+			if e.Source == nil || e.Source.Pointer != test.pointers[i] {
+				t.Errorf("%s: unexpected error, expected pointer: %s, actual: %+v", test.name, test.pointers[i], e)
+			}
+		}
+		if len(d.ops) > 0 {
+			t.Errorf("%s: Processor.Process should not dispatch any operations for invalid requests", test.name)
+		}
+	}
+}
+
+func TestProcessor_Process_WhenUnknownLid(t *testing.T) {
+	doc := decodeDocument(t, `
+	{
+		"atomic:operations": [
+			{"op": "add", "data": {"type": "articles", "lid": "a1"}},
+			{"op": "add", "data": {
+				"type": "comments",
+				"relationships": {"article": {"data": {"type": "articles", "lid": "a2"}}}
+			}}
+		]
+	}`)
+
+	d := &testDispatcher{}
+	p := atomic.Processor{Dispatcher: d}
+	_, err := p.Process(context.Background(), doc)
+
+	var errs jsonapi.Errors
+	if !errors.As(err, &errs) || errs[0].Source.Pointer != "/atomic:operations/1/data/relationships/article/data" {
+		t.Errorf("expected error pointing at unknown lid, actual: %+v", err)
+	}
+	if !d.rolledBack || d.committed {
+		t.Error("Processor.Process should roll back the transaction when an operation fails")
+	}
+}
+
+func TestProcessor_Process_WhenDispatchError(t *testing.T) {
+	doc := decodeDocument(t, `
+	{
+		"atomic:operations": [
+			{"op": "add", "data": {"type": "articles"}},
+			{"op": "remove", "ref": {"type": "articles", "id": "1"}}
+		]
+	}`)
+
+	d := &testDispatcher{fail: &jsonapi.Error{Status: "404", Title: "Not found"}}
+	p := atomic.Processor{Dispatcher: d}
+	_, err := p.Process(context.Background(), doc)
+
+	var errs jsonapi.Errors
+	if !errors.As(err, &errs) || errs[0].Status != "404" || errs[0].Source.Pointer != "/atomic:operations/1" {
+		t.Errorf("expected error from dispatcher pointing at operation, actual: %+v", err)
+	}
+	if !d.rolledBack || d.committed {
+		t.Error("Processor.Process should roll back the transaction when an operation fails")
+	}
+}
+
+func TestProcessor_Process_WhenSharedDispatchError(t *testing.T) {
+	const body = `{"atomic:operations": [
+		{"op": "add", "data": {"type": "articles"}},
+		{"op": "remove", "ref": {"type": "articles", "id": "1"}}
+	]}`
+	shared := &jsonapi.Error{Status: "404", Title: "Not found"}
+	sharedErrs := jsonapi.Errors{{Status: "409", Source: &jsonapi.ErrorSource{Pointer: "/data"}}}
+
+	for _, fail := range []error{shared, sharedErrs} {
+		// the same error returned on every request must not accumulate pointer prefixes
+		for n := 0; n < 2; n++ {
+			p := atomic.Processor{Dispatcher: &testDispatcher{fail: fail}}
+			_, err := p.Process(context.Background(), decodeDocument(t, body))
+
+			var errs jsonapi.Errors
+			if !errors.As(err, &errs) || !strings.HasPrefix(errs[0].Source.Pointer, "/atomic:operations/1") ||
+				strings.Count(errs[0].Source.Pointer, "/atomic:operations") != 1 {
+				t.Errorf("expected error from dispatcher pointing at operation once, actual: %+v", err)
+			}
+		}
+	}
+	if shared.Source != nil || sharedErrs[0].Source.Pointer != "/data" {
+		t.Errorf("expected errors returned by the dispatcher not to be changed: %+v %+v", shared, sharedErrs)
+	}
+}
+
+func TestProcessor_Process_WhenTooManyOperations(t *testing.T) {
+	doc := decodeDocument(t, `{"atomic:operations": [{"op": "remove", "ref": {"type": "a", "id": "1"}}, {"op": "remove", "ref": {"type": "a", "id": "2"}}]}`)
+	p := atomic.Processor{Dispatcher: &testDispatcher{}, MaxOperations: 1}
+
+	var errs jsonapi.Errors
+	if _, err := p.Process(context.Background(), doc); !errors.As(err, &errs) || errs[0].Status != "413" {
+		t.Errorf("expected 413 error when exceeding the maximum number of operations, actual: %+v", err)
+	}
+}
+
+func TestDocument_MarshalJSON(t *testing.T) {
+	result, _ := atomic.NewResult(&jsonapi.Resource{ID: "1", Type: "articles"})
+	doc := atomic.Document{Results: []atomic.Result{result, {}}}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("unexpected error when marshaling atomic document: %+v", err)
+	}
+	expected := `{"atomic:results":[{"data":{"id":"1","type":"articles"}},{}]}`
+	if string(b) != expected {
+		t.Errorf("unexpected JSON for atomic document, expected: %s, actual: %s", expected, b)
+	}
+}
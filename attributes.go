@@ -0,0 +1,247 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Attributes represents a JSON API attributes object, containing the resource object's data.
+//
+// The typed getters allow attributes to be read without type assertions, e.g. in a SetAttributes implementation:
+//
+//	func (a *Article) SetAttributes(as map[string]interface{}) error {
+//		attrs := jsonapi.Attributes(as)
+//		if v, ok := attrs.String("title"); ok {
+//			a.Title = v
+//		}
+//		return nil
+//	}
+//
+// http://jsonapi.org/format/#document-resource-object-attributes
+type Attributes map[string]interface{}
+
+// Add adds the key, value pair to the attributes object.
+// It overwrites any existing values associated with key.
+func (as Attributes) Add(key string, v interface{}) {
+	if as == nil {
+		return
+	}
+	as[key] = v
+}
+
+// Get returns the value associated with the given key and an existence check.
+func (as Attributes) Get(key string) (interface{}, bool) {
+	if as == nil {
+		return nil, false
+	}
+	v, ok := as[key]
+	return v, ok
+}
+
+// String returns the string value associated with the given key and an existence check.
+// Returns an empty string/false if the value associated with the key does not exist or is not of type string.
+func (as Attributes) String(key string) (string, bool) {
+	v, _ := as.Get(key)
+	return toString(v)
+}
+
+// Int returns the integer value associated with the given key and an existence check.
+// Returns 0/false if the value associated with the key does not exist, is not a number, or cannot be represented
+// as an int without loss.
+func (as Attributes) Int(key string) (int, bool) {
+	v, _ := as.Get(key)
+	return toInt(v)
+}
+
+// Int64 returns the 64-bit integer value associated with the given key and an existence check.
+// Returns 0/false if the value associated with the key does not exist, is not a number, or cannot be represented
+// as an int64 without loss.
+func (as Attributes) Int64(key string) (int64, bool) {
+	v, _ := as.Get(key)
+	return toInt64(v)
+}
+
+// Uint64 returns the unsigned 64-bit integer value associated with the given key and an existence check.
+// Returns 0/false if the value associated with the key does not exist, is not a number, or cannot be represented
+// as a uint64 without loss.
+func (as Attributes) Uint64(key string) (uint64, bool) {
+	v, _ := as.Get(key)
+	return toUint64(v)
+}
+
+// BigInt returns the arbitrary-precision integer value associated with the given key and an existence check.
+// Returns nil/false if the value associated with the key does not exist or is not an integral number.
+// Integers beyond 2^53 can only be read without loss when decoded as json.Number, see Decoder.UseNumber.
+func (as Attributes) BigInt(key string) (*big.Int, bool) {
+	v, _ := as.Get(key)
+	return toBigInt(v)
+}
+
+// BigRat returns the exact rational value associated with the given key and an existence check, e.g. for
+// decimal amounts. Returns nil/false if the value associated with the key does not exist or is not a number.
+// Decimals can only be read without loss when decoded as json.Number, see Decoder.UseNumber.
+func (as Attributes) BigRat(key string) (*big.Rat, bool) {
+	v, _ := as.Get(key)
+	return toBigRat(v)
+}
+
+// Float64 returns the floating point value associated with the given key and an existence check.
+// Returns 0/false if the value associated with the key does not exist or is not a number.
+func (as Attributes) Float64(key string) (float64, bool) {
+	v, _ := as.Get(key)
+	return toFloat64(v)
+}
+
+// Bool returns the boolean value associated with the given key and an existence check.
+// Returns false/false if the value associated with the key does not exist or is not of type bool.
+func (as Attributes) Bool(key string) (bool, bool) {
+	v, _ := as.Get(key)
+	return toBool(v)
+}
+
+// Time returns the time value associated with the given key and an existence check.
+// The value must either be a time.Time or a string in RFC 3339 format.
+// Returns the zero time/false if the value associated with the key does not exist or cannot be parsed.
+func (as Attributes) Time(key string) (time.Time, bool) {
+	v, _ := as.Get(key)
+	return toTime(v)
+}
+
+// StringSlice returns the string slice value associated with the given key and an existence check.
+// Returns nil/false if the value associated with the key does not exist or is not an array of strings.
+func (as Attributes) StringSlice(key string) ([]string, bool) {
+	v, _ := as.Get(key)
+	switch typ := v.(type) {
+	case []string:
+		return typ, true
+	case []interface{}:
+		ss := make([]string, len(typ))
+		for i, v := range typ {
+			s, ok := v.(string)
+			if !ok {
+				return nil, false
+			}
+			ss[i] = s
+		}
+		return ss, true
+	}
+	return nil, false
+}
+
+// Has returns true if the key exists, even if its value is null.
+func (as Attributes) Has(key string) bool {
+	_, ok := as.Get(key)
+	return ok
+}
+
+// IsNull returns true if the key exists and its value is null.
+func (as Attributes) IsNull(key string) bool {
+	v, ok := as.Get(key)
+	return ok && v == nil
+}
+
+// Delete deletes the value associated with the given key.
+func (as Attributes) Delete(key string) {
+	if as == nil {
+		return
+	}
+	delete(as, key)
+}
+
+// HasAttribute returns true if the resource's attributes contain the key, even if its value is null.
+// For a resource decoded from a PATCH request, this tells whether the attribute should be updated at all.
+func (r *Resource) HasAttribute(key string) bool {
+	return r.Attributes.Has(key)
+}
+
+// HasRelationship returns true if the resource's relationships contain the key, even if its value is null.
+func (r *Resource) HasRelationship(key string) bool {
+	_, ok := r.Relationships.Get(key)
+	return ok
+}
+
+// DecodeAttributes decodes the resource's attributes into dst, which must be a non-nil pointer, using the
+// "encoding/json" package and therefore the `json` struct tags of dst's fields. Attributes without a
+// matching field are ignored.
+//
+// Unlike the adapter interfaces, DecodeAttributes relies on reflection; it is entirely optional.
+//
+// If any attribute cannot be decoded into its field, DecodeAttributes returns Errors containing a
+// 400 Bad Request error for each such attribute, with a source pointer such as "/data/attributes/title".
+func (r *Resource) DecodeAttributes(dst interface{}) error {
+	if rv := reflect.ValueOf(dst); rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &json.InvalidUnmarshalError{Type: reflect.TypeOf(dst)}
+	}
+
+	keys := make([]string, 0, len(r.Attributes))
+	for key := range r.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// decode each attribute separately, so that any error can be traced back to it
+	var errs Errors
+	for _, key := range keys {
+		b, err := json.Marshal(map[string]interface{}{key: r.Attributes[key]})
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, dst); err != nil {
+			errs = append(errs, attributeError(key, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// attributeError converts an error from decoding the attribute key into a JSON API error.
+func attributeError(key string, err error) Error {
+	path := []string{"data", "attributes", key}
+	detail := err.Error()
+
+	if err, ok := err.(*json.UnmarshalTypeError); ok {
+		// the field path of nested values also includes key itself
+		if fields := strings.Split(err.Field, "."); len(err.Field) > 0 && len(fields) > 1 {
+			path = append(path, fields[1:]...)
+		}
+		detail = fmt.Sprintf("attribute %q must be of type %s, got %s", key, jsonTypeName(err.Type), err.Value)
+	}
+
+	return Error{
+		Status: fmt.Sprint(http.StatusBadRequest),
+		Title:  "Invalid attribute",
+		Detail: detail,
+		Source: &ErrorSource{Pointer: jsonPointer(path...)},
+	}
+}
+
+// jsonTypeName returns the name of the JSON type a Go type is decoded from.
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return t.String()
+}
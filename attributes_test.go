@@ -0,0 +1,156 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/smotes/jsonapi"
+)
+
+func TestAttributes_Add_WhenNil(t *testing.T) {
+	var as jsonapi.Attributes
+	defer catchPanic(t, "Attributes", "Add")
+	as.Add("foo", "bar")
+}
+
+func TestAttributes_Get_WhenNil(t *testing.T) {
+	var as jsonapi.Attributes
+	defer catchPanic(t, "Attributes", "Get")
+	if v, ok := as.Get("foo"); v != nil || ok {
+		t.Error("Attributes.Get should return nil/false when Attributes is nil")
+	}
+	as.String("foo")
+	as.StringSlice("foo")
+}
+
+func TestAttributes_Delete_WhenNil(t *testing.T) {
+	var as jsonapi.Attributes
+	defer catchPanic(t, "Attributes", "Delete")
+	as.Delete("foo")
+}
+
+func TestAttributes_Getters(t *testing.T) {
+	as := jsonapi.Attributes{}
+	if err := json.Unmarshal([]byte(`
+	{
+		"title": "foo",
+		"views": 42,
+		"rating": 4.5,
+		"published": true,
+		"created": "2016-01-02T03:04:05Z",
+		"tags": ["a", "b"],
+		"mixed": ["a", 1]
+	}`), &as); err != nil {
+		t.Fatalf("unexpected error when unmarshaling attributes: %+v", err)
+	}
+
+	if v, ok := as.String("title"); !ok || v != "foo" {
+		t.Errorf("unexpected value from Attributes.String: %v", v)
+	}
+	if v, ok := as.Int("views"); !ok || v != 42 {
+		t.Errorf("unexpected value from Attributes.Int: %v", v)
+	}
+	if v, ok := as.Int64("views"); !ok || v != 42 {
+		t.Errorf("unexpected value from Attributes.Int64: %v", v)
+	}
+	if _, ok := as.Int64("rating"); ok {
+		t.Error("Attributes.Int64 should return false for non-integral numbers")
+	}
+	if v, ok := as.Float64("rating"); !ok || v != 4.5 {
+		t.Errorf("unexpected value from Attributes.Float64: %v", v)
+	}
+	if v, ok := as.Bool("published"); !ok || !v {
+		t.Errorf("unexpected value from Attributes.Bool: %v", v)
+	}
+	if v, ok := as.Time("created"); !ok || !v.Equal(time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("unexpected value from Attributes.Time: %v", v)
+	}
+	if v, ok := as.StringSlice("tags"); !ok || len(v) != 2 || v[0] != "a" || v[1] != "b" {
+		t.Errorf("unexpected value from Attributes.StringSlice: %v", v)
+	}
+	if _, ok := as.StringSlice("mixed"); ok {
+		t.Error("Attributes.StringSlice should return false for arrays containing non-string values")
+	}
+	if _, ok := as.String("missing"); ok {
+		t.Error("Attributes.String should return false for missing keys")
+	}
+}
+
+type articleAttributes struct {
+	Title   string    `json:"title"`
+	Views   int       `json:"views"`
+	Created time.Time `json:"created"`
+	Author  struct {
+		Name string `json:"name"`
+	} `json:"author"`
+}
+
+func TestResource_DecodeAttributes(t *testing.T) {
+	r := jsonapi.Resource{}
+	if err := json.Unmarshal([]byte(`
+	{
+		"id": "1",
+		"type": "articles",
+		"attributes": {
+			"title": "foo",
+			"views": 42,
+			"created": "2016-01-02T03:04:05Z",
+			"author": {"name": "bar"},
+			"unknown": true
+		}
+	}`), &r); err != nil {
+		t.Fatalf("unexpected error when unmarshaling resource: %+v", err)
+	}
+
+	var attrs articleAttributes
+	if err := r.DecodeAttributes(&attrs); err != nil {
+		t.Fatalf("unexpected error from Resource.DecodeAttributes: %+v", err)
+	}
+	if attrs.Title != "foo" || attrs.Views != 42 || attrs.Author.Name != "bar" || attrs.Created.Year() != 2016 {
+		t.Errorf("unexpected result from Resource.DecodeAttributes: %+v", attrs)
+	}
+}
+
+func TestResource_DecodeAttributes_WhenInvalid(t *testing.T) {
+	r := jsonapi.Resource{
+		Attributes: jsonapi.Attributes{
+			"title":   42,
+			"views":   "many",
+			"created": "yesterday",
+			"author":  map[string]interface{}{"name": false},
+		},
+	}
+
+	var attrs articleAttributes
+	err := r.DecodeAttributes(&attrs)
+
+	var errs jsonapi.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected Resource.DecodeAttributes to return Errors, actual: %+v", err)
+	}
+
+	expected := []string{
+		"/data/attributes/author/name",
+		"/data/attributes/created",
+		"/data/attributes/title",
+		"/data/attributes/views",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("unexpected number of errors from Resource.DecodeAttributes, expected: %d, actual: %d",
+			len(expected), len(errs))
+	}
+	for i, e := range errs {
+		if e.Status != "400" || e.Source == nil || e.Source.Pointer != expected[i] {
+			t.Errorf("unexpected error from Resource.DecodeAttributes, expected pointer: %s, actual: %+v", expected[i], e)
+		}
+	}
+}
+
+func TestResource_DecodeAttributes_WhenNotPointer(t *testing.T) {
+	r := jsonapi.Resource{}
+	if err := r.DecodeAttributes(articleAttributes{}); err == nil {
+		t.Error("Resource.DecodeAttributes should return an error when dst is not a pointer")
+	}
+}
@@ -0,0 +1,345 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// BaseURLResolver resolves the external base URL of a request, i.e. the scheme, host and path prefix as seen
+// by the client, for use when building absolute links.
+//
+// The "Forwarded" (RFC 7239) and "X-Forwarded-Proto", "X-Forwarded-Host", "X-Forwarded-Port" and
+// "X-Forwarded-Prefix" headers are only honored when the request was received from a trusted proxy, as
+// otherwise any client could forge the links returned by the API. If the headers are not trusted or not present,
+// the scheme and host of the request itself are used.
+//
+// As proxies may append to the "X-Forwarded-*" headers rather than overwrite them, only their rightmost value,
+// which was added by the trusted proxy the request was received from, is used. The "Forwarded" header is walked
+// from the right across all trusted proxies instead, as its elements identify the proxy that added them.
+type BaseURLResolver struct {
+	// TrustedProxies is the allowlist of proxy addresses, given either as single IP addresses or CIDR ranges.
+	TrustedProxies []string
+
+	// Path is the path prefix appended to the resolved scheme and host, e.g. "/api/v1".
+	Path string
+}
+
+// Resolve returns the external base URL for the request r.
+func (res *BaseURLResolver) Resolve(r *http.Request) *url.URL {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   r.Host,
+	}
+	if r.TLS != nil {
+		u.Scheme = "https"
+	}
+
+	prefix := ""
+	if res.isTrusted(r.RemoteAddr) {
+		if fwd := r.Header.Get("Forwarded"); len(fwd) > 0 {
+			if proto, host, ok := res.forwarded(r.Header["Forwarded"]); ok {
+				if len(proto) > 0 {
+					u.Scheme = strings.ToLower(proto)
+				}
+				if len(host) > 0 {
+					u.Host = host
+				}
+			}
+		} else {
+			if proto := lastHeaderValue(r.Header, "X-Forwarded-Proto"); len(proto) > 0 {
+				u.Scheme = strings.ToLower(proto)
+			}
+			if host := lastHeaderValue(r.Header, "X-Forwarded-Host"); len(host) > 0 {
+				u.Host = host
+			}
+			if port := lastHeaderValue(r.Header, "X-Forwarded-Port"); len(port) > 0 {
+				u.Host = joinHostPort(u.Host, port, u.Scheme)
+			}
+		}
+		prefix = lastHeaderValue(r.Header, "X-Forwarded-Prefix")
+	}
+
+	u.Path = joinPath(prefix, res.Path)
+	return u
+}
+
+// forwarded walks the elements of the "Forwarded" header from the closest proxy to the furthest, and returns
+// the proto and host of the element appended by the outermost trusted proxy.
+func (res *BaseURLResolver) forwarded(values []string) (proto, host string, ok bool) {
+	var elems []map[string]string
+	for _, v := range values {
+		for _, elem := range splitQuoted(v, ',') {
+			elems = append(elems, parseForwardedElement(elem))
+		}
+	}
+
+	for i := len(elems) - 1; i >= 0; i-- {
+		proto, host, ok = elems[i]["proto"], elems[i]["host"], true
+		if i == 0 || !res.isTrusted(forwardedNode(elems[i]["for"])) {
+			break
+		}
+	}
+	return
+}
+
+func (res *BaseURLResolver) isTrusted(addr string) bool {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip := net.ParseIP(strings.Trim(addr, "[]"))
+	if ip == nil {
+		return false
+	}
+
+	for _, proxy := range res.TrustedProxies {
+		if strings.Contains(proxy, "/") {
+			if _, n, err := net.ParseCIDR(proxy); err == nil && n.Contains(ip) {
+				return true
+			}
+		} else if pip := net.ParseIP(proxy); pip != nil && pip.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// AbsoluteLinks converts all relative link hrefs in the document to absolute ones using the base URL,
+// including the links of any primary or included resources, their relationships and any errors.
+//
+// See Links.Absolute for details on how hrefs are resolved.
+func (doc *Document) AbsoluteLinks(base *url.URL) error {
+	return doc.rewriteLinks(func(href string) string {
+		return absoluteHref(base, href)
+	})
+}
+
+// RelativeLinks converts all absolute link hrefs in the document that point below the base URL to relative ones,
+// reversing AbsoluteLinks.
+func (doc *Document) RelativeLinks(base *url.URL) error {
+	return doc.rewriteLinks(func(href string) string {
+		return relativeHref(base, href)
+	})
+}
+
+func (doc *Document) rewriteLinks(fn func(string) string) error {
+	doc.Links.rewrite(fn)
+	for i := range doc.Errors {
+		doc.Errors[i].Links.rewrite(fn)
+	}
+	for i := range doc.Included {
+		doc.Included[i].rewriteLinks(fn)
+	}
+
+	// primary data is kept as raw JSON, so it must be decoded in order to rewrite its links
+	data := strings.TrimSpace(string(doc.Data))
+	switch {
+	case len(data) == 0 || data == "null":
+		return nil
+
+	case data[0] == '[':
+		var rs []Resource
+		if err := decodeData(doc.Data, &rs); err != nil {
+			return err
+		}
+		for i := range rs {
+			rs[i].rewriteLinks(fn)
+		}
+		return doc.setData(rs)
+
+	default:
+		var r Resource
+		if err := decodeData(doc.Data, &r); err != nil {
+			return err
+		}
+		r.rewriteLinks(fn)
+		return doc.setData(&r)
+	}
+}
+
+// decodeData decodes primary data into v, keeping numbers as json.Number so that encoding it again does not change
+// any attribute or meta values.
+func decodeData(b []byte, v interface{}) error {
+	dec := NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func (doc *Document) setData(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	doc.Data = b
+	return nil
+}
+
+func (r *Resource) rewriteLinks(fn func(string) string) {
+	r.Links.rewrite(fn)
+	for _, rel := range r.Relationships {
+		if rel != nil {
+			rel.Links.rewrite(fn)
+		}
+	}
+}
+
+// Absolute converts all relative link hrefs to absolute ones using the base URL.
+//
+// Relative hrefs are considered relative to the root of the API, so the path of the base URL is always kept,
+// e.g. "/articles/1" resolved against "https://example.com/api" becomes "https://example.com/api/articles/1".
+// Hrefs which are already absolute are left untouched.
+func (ls Links) Absolute(base *url.URL) {
+	ls.rewrite(func(href string) string {
+		return absoluteHref(base, href)
+	})
+}
+
+// Relative converts all absolute link hrefs pointing below the base URL to relative ones, reversing Absolute.
+// Hrefs pointing elsewhere are left untouched.
+func (ls Links) Relative(base *url.URL) {
+	ls.rewrite(func(href string) string {
+		return relativeHref(base, href)
+	})
+}
+
+// rewrite replaces each href in the links object with the result of fn. Link objects are copied rather than
+// modified in place, as they may be shared between several links objects.
+func (ls Links) rewrite(fn func(string) string) {
+	for key, v := range ls {
+		switch typ := v.(type) {
+		case string:
+			ls[key] = fn(typ)
+		case *Link:
+			if typ != nil {
+				ls[key] = typ.rewritten(fn)
+			}
+		case map[string]interface{}:
+			ls[key] = linkFromMap(typ).rewritten(fn)
+		}
+	}
+}
+
+func (l *Link) rewritten(fn func(string) string) *Link {
+	c := *l
+	c.Href = fn(c.Href)
+	if c.DescribedBy != nil {
+		c.DescribedBy = c.DescribedBy.rewritten(fn)
+	}
+	return &c
+}
+
+func absoluteHref(base *url.URL, href string) string {
+	u, err := url.Parse(href)
+	if err != nil || u.IsAbs() || len(u.Host) > 0 {
+		return href
+	}
+
+	abs := *base
+	abs.Path = joinPath(base.Path, u.Path)
+	abs.RawPath = ""
+	abs.RawQuery = u.RawQuery
+	abs.Fragment = u.Fragment
+	return abs.String()
+}
+
+func relativeHref(base *url.URL, href string) string {
+	u, err := url.Parse(href)
+	if err != nil || !strings.EqualFold(u.Scheme, base.Scheme) || !strings.EqualFold(u.Host, base.Host) {
+		return href
+	}
+
+	prefix := strings.TrimSuffix(base.Path, "/")
+	if u.Path != prefix && !strings.HasPrefix(u.Path, prefix+"/") {
+		return href
+	}
+
+	rel := url.URL{
+		Path:     strings.TrimPrefix(u.Path, prefix),
+		RawQuery: u.RawQuery,
+		Fragment: u.Fragment,
+	}
+	if len(rel.Path) == 0 {
+		rel.Path = "/"
+	}
+	return rel.String()
+}
+
+// helpers
+
+// lastHeaderValue returns the rightmost element of the comma-separated list header key, across all its lines.
+// Proxies which append to such lists rather than overwriting them keep any values sent by the client on the left,
+// so only the rightmost one, added by the trusted proxy the request was received from, can be relied upon.
+func lastHeaderValue(h http.Header, key string) string {
+	values := h[http.CanonicalHeaderKey(key)]
+	if len(values) == 0 {
+		return ""
+	}
+	v := values[len(values)-1]
+	if i := strings.LastIndexByte(v, ','); i >= 0 {
+		v = v[i+1:]
+	}
+	return strings.TrimSpace(v)
+}
+
+func joinHostPort(host, port, scheme string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), port)
+}
+
+func joinPath(a, b string) string {
+	p := strings.TrimSuffix(a, "/") + "/" + strings.TrimPrefix(b, "/")
+	if p == "/" {
+		return ""
+	}
+	return strings.TrimSuffix(p, "/")
+}
+
+func parseForwardedElement(elem string) map[string]string {
+	m := map[string]string{}
+	for _, pair := range splitQuoted(elem, ';') {
+		i := strings.IndexByte(pair, '=')
+		if i < 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(pair[:i]))
+		m[key] = strings.Trim(strings.TrimSpace(pair[i+1:]), `"`)
+	}
+	return m
+}
+
+// forwardedNode strips the optional port and brackets from a "for" node identifier of the "Forwarded" header.
+func forwardedNode(node string) string {
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		return host
+	}
+	return strings.Trim(node, "[]")
+}
+
+// splitQuoted splits s by sep, ignoring any separators within quoted strings.
+func splitQuoted(s string, sep byte) []string {
+	var (
+		parts  []string
+		quoted bool
+		start  int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
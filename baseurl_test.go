@@ -0,0 +1,217 @@
+package jsonapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+func TestBaseURLResolver_Resolve(t *testing.T) {
+	res := jsonapi.BaseURLResolver{
+		TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"},
+		Path:           "/api",
+	}
+
+	tests := []struct {
+		name     string
+		remote   string
+		header   http.Header
+		expected string
+	}{
+		{
+			name:     "no proxy",
+			remote:   "10.1.2.3:1234",
+			expected: "http://internal/api",
+		},
+		{
+			name:     "untrusted proxy",
+			remote:   "8.8.8.8:1234",
+			header:   http.Header{"X-Forwarded-Proto": {"https"}, "X-Forwarded-Host": {"example.com"}},
+			expected: "http://internal/api",
+		},
+		{
+			name:   "x-forwarded headers",
+			remote: "192.168.1.1:1234",
+			header: http.Header{
+				"X-Forwarded-Proto":  {"https"},
+				"X-Forwarded-Host":   {"proxy.local, example.com"},
+				"X-Forwarded-Port":   {"8443"},
+				"X-Forwarded-Prefix": {"/svc"},
+			},
+			expected: "https://example.com:8443/svc/api",
+		},
+		{
+			name:   "x-forwarded headers appended to values sent by the client",
+			remote: "192.168.1.1:1234",
+			header: http.Header{
+				"X-Forwarded-Proto": {"http", "https"},
+				"X-Forwarded-Host":  {"evil.com, example.com"},
+				"X-Forwarded-Port":  {"1234,443"},
+			},
+			expected: "https://example.com/api",
+		},
+		{
+			name:     "forwarded header",
+			remote:   "10.0.0.1:1234",
+			header:   http.Header{"Forwarded": {`for=1.2.3.4;proto=https;host="example.com"`}},
+			expected: "https://example.com/api",
+		},
+		{
+			name:   "forwarded header with chain of proxies",
+			remote: "10.0.0.1:1234",
+			header: http.Header{"Forwarded": {
+				`for=1.2.3.4;proto=https;host=example.com, for="[2001:db8::1]";proto=http;host=evil.com`,
+				`for=10.0.0.2;proto=http;host=internal`,
+			}},
+			expected: "http://evil.com/api",
+		},
+		{
+			name:   "forwarded header with trusted chain",
+			remote: "10.0.0.1:1234",
+			header: http.Header{"Forwarded": {
+				`for=1.2.3.4;proto=https;host=example.com, for=10.0.0.3:80;proto=http;host=internal`,
+			}},
+			expected: "https://example.com/api",
+		},
+	}
+
+	for _, test := range tests {
+		r, _ := http.NewRequest("GET", "http://internal/articles", nil)
+		r.RemoteAddr = test.remote
+		if test.header != nil {
+			r.Header = test.header
+		}
+		if actual := res.Resolve(r).String(); actual != test.expected {
+			t.Errorf("%s: unexpected base URL from BaseURLResolver.Resolve, expected: %s, actual: %s",
+				test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestLinks_Absolute(t *testing.T) {
+	base, _ := url.Parse("https://example.com/api")
+	ls := jsonapi.Links{}
+	ls.AddString("self", "/articles/1?include=author")
+	ls.AddString("external", "http://other.com/articles/1")
+	shared := &jsonapi.Link{Href: "/articles/1/comments"}
+	ls.Add("related", shared)
+
+	ls.Absolute(base)
+	if actual, _ := ls.GetString("self"); actual != "https://example.com/api/articles/1?include=author" {
+		t.Errorf("unexpected href after Links.Absolute, actual: %s", actual)
+	}
+	if actual, _ := ls.GetString("external"); actual != "http://other.com/articles/1" {
+		t.Errorf("Links.Absolute should not modify absolute hrefs, actual: %s", actual)
+	}
+	if actual, _ := ls.Get("related"); actual.Href != "https://example.com/api/articles/1/comments" {
+		t.Errorf("unexpected href after Links.Absolute, actual: %s", actual.Href)
+	}
+	if shared.Href != "/articles/1/comments" {
+		t.Error("Links.Absolute should not modify link objects in place")
+	}
+
+	ls.Relative(base)
+	if actual, _ := ls.GetString("self"); actual != "/articles/1?include=author" {
+		t.Errorf("unexpected href after Links.Relative, actual: %s", actual)
+	}
+	if actual, _ := ls.GetString("external"); actual != "http://other.com/articles/1" {
+		t.Errorf("Links.Relative should not modify hrefs outside of the base URL, actual: %s", actual)
+	}
+	if actual, _ := ls.Get("related"); actual.Href != "/articles/1/comments" {
+		t.Errorf("unexpected href after Links.Relative, actual: %s", actual.Href)
+	}
+}
+
+func TestDocument_AbsoluteLinks(t *testing.T) {
+	base, _ := url.Parse("https://example.com")
+	doc := jsonapi.Document{}
+	if err := json.Unmarshal([]byte(`
+	{
+		"data": [{
+			"id": "1",
+			"type": "articles",
+			"links": {"self": "/articles/1"},
+			"relationships": {
+				"author": {"links": {"related": {"href": "/articles/1/author"}}}
+			}
+		}],
+		"errors": [{"links": {"about": "/errors/1"}}],
+		"included": [{"id": "42", "type": "people", "links": {"self": "/people/42"}}],
+		"links": {"self": "/articles"}
+	}`), &doc); err != nil {
+		t.Fatalf("unexpected error when unmarshaling document: %+v", err)
+	}
+
+	if err := doc.AbsoluteLinks(base); err != nil {
+		t.Fatalf("unexpected error from Document.AbsoluteLinks: %+v", err)
+	}
+
+	var data []jsonapi.Resource
+	if err := json.Unmarshal(doc.Data, &data); err != nil {
+		t.Fatalf("unexpected error when unmarshaling primary data: %+v", err)
+	}
+	rel, _ := data[0].Relationships.Get("author")
+	related, _ := rel.Links.Get("related")
+
+	for _, test := range []struct{ actual, expected string }{
+		{hrefOf(doc.Links, "self"), "https://example.com/articles"},
+		{hrefOf(doc.Errors[0].Links, "about"), "https://example.com/errors/1"},
+		{hrefOf(doc.Included[0].Links, "self"), "https://example.com/people/42"},
+		{hrefOf(data[0].Links, "self"), "https://example.com/articles/1"},
+		{related.Href, "https://example.com/articles/1/author"},
+	} {
+		if test.actual != test.expected {
+			t.Errorf("unexpected href after Document.AbsoluteLinks, expected: %s, actual: %s", test.expected, test.actual)
+		}
+	}
+
+	if err := doc.RelativeLinks(base); err != nil {
+		t.Fatalf("unexpected error from Document.RelativeLinks: %+v", err)
+	}
+	if actual := hrefOf(doc.Links, "self"); actual != "/articles" {
+		t.Errorf("unexpected href after Document.RelativeLinks, actual: %s", actual)
+	}
+}
+
+func hrefOf(ls jsonapi.Links, key string) string {
+	if s, ok := ls.GetString(key); ok {
+		return s
+	}
+	if l, ok := ls.Get(key); ok {
+		return l.Href
+	}
+	return ""
+}
+
+func TestDocument_AbsoluteLinks_Numbers(t *testing.T) {
+	base, _ := url.Parse("https://example.com")
+	data := `{"type":"articles","id":"1","attributes":{"n":9007199254740993},"links":{"self":"/articles/1"},` +
+		`"meta":{"m":1.10}}`
+	doc := jsonapi.Document{Data: []byte(data)}
+
+	for _, rewrite := range []func(*url.URL) error{doc.AbsoluteLinks, doc.RelativeLinks} {
+		if err := rewrite(base); err != nil {
+			t.Fatalf("unexpected error rewriting links: %+v", err)
+		}
+	}
+
+	dec := jsonapi.NewDecoder(bytes.NewReader(doc.Data))
+	dec.UseNumber()
+	r := jsonapi.Resource{}
+	if err := dec.Decode(&r); err != nil {
+		t.Fatalf("unexpected error when decoding primary data: %+v", err)
+	}
+	if n := r.Attributes["n"]; n != json.Number("9007199254740993") {
+		t.Errorf("expected rewriting links not to change numbers, actual: %v in %s", n, doc.Data)
+	}
+	if m := r.Meta["m"]; m != json.Number("1.10") {
+		t.Errorf("expected rewriting links not to change numbers, actual: %v in %s", m, doc.Data)
+	}
+	if self := hrefOf(r.Links, "self"); self != "/articles/1" {
+		t.Errorf("unexpected href after rewriting links: %s", self)
+	}
+}
@@ -0,0 +1,132 @@
+package jsonapi
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ClientIDPolicy declares whether clients may send their own IDs when creating resources.
+//
+// http://jsonapi.org/format/#crud-creating-client-ids
+type ClientIDPolicy int
+
+const (
+	// ClientIDAllowed accepts resources with or without a client-generated ID, which is the default.
+	ClientIDAllowed ClientIDPolicy = iota
+
+	// ClientIDForbidden rejects resources with a client-generated ID with a 403 Forbidden error.
+	ClientIDForbidden
+
+	// ClientIDRequired rejects resources without a client-generated ID with a 400 Bad Request error.
+	ClientIDRequired
+)
+
+// WithClientIDPolicy sets the policy for client-generated IDs, which is only applied along with ForCreate.
+func WithClientIDPolicy(policy ClientIDPolicy) FromResourceOption {
+	return func(o *fromResourceOptions) {
+		o.clientIDPolicy = policy
+	}
+}
+
+// WithClientIDValidator sets a function validating client-generated IDs when creating resources, e.g. ValidateUUID.
+//
+// If fn returns a *Error, it is returned as is; any other error is returned as a 400 Bad Request error, as the id
+// is malformed rather than forbidden, with the source pointer "/data/id" and the error's message as detail, so
+// the message should be meant for clients.
+func WithClientIDValidator(fn func(id string) error) FromResourceOption {
+	return func(o *fromResourceOptions) {
+		o.clientIDValidator = fn
+	}
+}
+
+// WithClientIDLookup sets a function reporting whether a resource of the given type and ID already exists,
+// which is called for client-generated IDs when creating resources. Existing IDs are rejected with a
+// 409 Conflict error, as required by the JSON API specification. Any error returned by fn is returned as is.
+func WithClientIDLookup(fn func(typ, id string) (bool, error)) FromResourceOption {
+	return func(o *fromResourceOptions) {
+		o.clientIDLookup = fn
+	}
+}
+
+// ValidateUUID returns a 400 Bad Request *Error with the source pointer "/data/id" unless id is a UUID in its
+// canonical, hyphenated textual form, e.g. "123e4567-e89b-12d3-a456-426614174000".
+func ValidateUUID(id string) error {
+	if len(id) != 36 {
+		return invalidID("id must be a UUID")
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return invalidID("id must be a UUID")
+			}
+		default:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return invalidID("id must be a UUID")
+			}
+		}
+	}
+	return nil
+}
+
+// checkClientID applies the client-generated ID policy, validator and lookup to r.
+func (o *fromResourceOptions) checkClientID(r *Resource) error {
+	if len(r.ID) == 0 {
+		if o.clientIDPolicy == ClientIDRequired {
+			return &Error{
+				Status: fmt.Sprint(http.StatusBadRequest),
+				Title:  "Missing id",
+				Detail: "resource must be created with a client-generated id",
+				Source: &ErrorSource{Pointer: "/data"},
+			}
+		}
+		return nil
+	}
+
+	if o.clientIDPolicy == ClientIDForbidden {
+		return &Error{
+			Status: fmt.Sprint(http.StatusForbidden),
+			Title:  "Client-generated id not supported",
+			Detail: "resource must not be created with a client-generated id",
+			Source: &ErrorSource{Pointer: "/data/id"},
+		}
+	}
+
+	if o.clientIDValidator != nil {
+		if err := o.clientIDValidator(r.ID); err != nil {
+			if e, ok := err.(*Error); ok {
+				return e
+			}
+			return invalidID(err.Error())
+		}
+	}
+
+	if o.clientIDLookup != nil {
+		exists, err := o.clientIDLookup(r.Type, r.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return &Error{
+				Status: fmt.Sprint(http.StatusConflict),
+				Title:  "Resource already exists",
+				Detail: fmt.Sprintf("a resource of type %q with id %q already exists", r.Type, r.ID),
+				Source: &ErrorSource{Pointer: "/data/id"},
+			}
+		}
+	}
+
+	return nil
+}
+
+// helpers
+
+func invalidID(detail string) *Error {
+	return &Error{
+		Status: fmt.Sprint(http.StatusBadRequest),
+		Title:  "Invalid id",
+		Detail: detail,
+		Source: &ErrorSource{Pointer: "/data/id"},
+	}
+}
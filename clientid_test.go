@@ -0,0 +1,91 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+func TestValidateUUID(t *testing.T) {
+	for id, valid := range map[string]bool{
+		"123e4567-e89b-12d3-a456-426614174000": true,
+		"123E4567-E89B-12D3-A456-426614174000": true,
+		"123e4567e89b12d3a456426614174000":     false,
+		"123e4567-e89b-12d3-a456-42661417400g": false,
+		"1":                                    false,
+	} {
+		if err := jsonapi.ValidateUUID(id); (err == nil) != valid {
+			t.Errorf("unexpected result from ValidateUUID for %s: %v", id, err)
+		}
+		if e, ok := jsonapi.ValidateUUID(id).(*jsonapi.Error); !valid && (!ok || e.Detail != "id must be a UUID") {
+			t.Errorf("expected client-facing error from ValidateUUID for %s, actual: %+v", id, e)
+		}
+	}
+}
+
+func TestFromResource_ClientIDPolicy(t *testing.T) {
+	const existing = "123e4567-e89b-12d3-a456-426614174000"
+	lookup := func(typ, id string) (bool, error) {
+		return typ == "articles" && id == existing, nil
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		opts    []jsonapi.FromResourceOption
+		status  string
+		pointer string
+	}{
+		{"allowed by default", "1", nil, "", ""},
+		{"forbidden", "1", []jsonapi.FromResourceOption{jsonapi.WithClientIDPolicy(jsonapi.ClientIDForbidden)}, "403", "/data/id"},
+		{"forbidden without id", "", []jsonapi.FromResourceOption{jsonapi.WithClientIDPolicy(jsonapi.ClientIDForbidden)}, "", ""},
+		{"required without id", "", []jsonapi.FromResourceOption{jsonapi.WithClientIDPolicy(jsonapi.ClientIDRequired)}, "400", "/data"},
+		{"invalid", "1", []jsonapi.FromResourceOption{jsonapi.WithClientIDValidator(jsonapi.ValidateUUID)}, "400", "/data/id"},
+		{"invalid by custom validator", "1", []jsonapi.FromResourceOption{jsonapi.WithClientIDValidator(func(string) error {
+			return errors.New("id must be a number greater than 1")
+		})}, "400", "/data/id"},
+		{"existing", existing, []jsonapi.FromResourceOption{jsonapi.WithClientIDValidator(jsonapi.ValidateUUID),
+			jsonapi.WithClientIDLookup(lookup)}, "409", "/data/id"},
+		{"new", "223e4567-e89b-12d3-a456-426614174000", []jsonapi.FromResourceOption{
+			jsonapi.WithClientIDPolicy(jsonapi.ClientIDRequired), jsonapi.WithClientIDLookup(lookup)}, "", ""},
+	}
+
+	for _, test := range tests {
+		r := jsonapi.Resource{ID: test.id, Type: "articles"}
+		adapter := accessWriteAdapter{}
+		err := jsonapi.FromResource(&adapter, &r, true, append(test.opts, jsonapi.ForCreate())...)
+
+		if len(test.status) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error from FromResource: %+v", test.name, err)
+			}
+			continue
+		}
+
+		var e *jsonapi.Error
+		if !errors.As(err, &e) || e.Status != test.status || e.Source.Pointer != test.pointer {
+			t.Errorf("%s: expected FromResource to return error %s %s, actual: %+v", test.name, test.status, test.pointer, err)
+		}
+	}
+}
+
+func TestFromResource_ClientIDPolicy_WhenNotCreating(t *testing.T) {
+	r := jsonapi.Resource{ID: "1", Type: "articles"}
+	adapter := accessWriteAdapter{}
+	if err := jsonapi.FromResource(&adapter, &r, true, jsonapi.ForUpdate(),
+		jsonapi.WithClientIDPolicy(jsonapi.ClientIDForbidden)); err != nil {
+		t.Errorf("client-generated ID policy should only apply when creating resources, actual: %+v", err)
+	}
+}
+
+func TestFromResource_ClientIDLookup_WhenError(t *testing.T) {
+	r := jsonapi.Resource{ID: "1", Type: "articles"}
+	adapter := accessWriteAdapter{}
+	lookup := func(typ, id string) (bool, error) {
+		return false, testErr
+	}
+	if err := jsonapi.FromResource(&adapter, &r, true, jsonapi.ForCreate(), jsonapi.WithClientIDLookup(lookup)); err != testErr {
+		t.Errorf("expected FromResource to return error from lookup, actual: %+v", err)
+	}
+}
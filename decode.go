@@ -0,0 +1,768 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Decoder reads and decodes JSON API documents and their members from an input stream.
+//
+// Documents, resources and the other types in this package can be decoded with json.Unmarshal as well; Decoder
+// exists for the cases where the decoding must be configured, e.g. to keep numbers as json.Number, as options set
+// on a json.Decoder do not carry over to members decoded separately, such as links.
+type Decoder struct {
+	dec *json.Decoder
+	d   decoder
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r)}
+}
+
+// UseNumber causes the Decoder to decode all numbers within free-form members, i.e. attributes and meta objects,
+// as json.Number instead of float64. This avoids any loss of precision for 64-bit integer IDs or decimal amounts,
+// which can then be read with the typed getters of Attributes and Meta, such as Int64, Uint64 or BigRat.
+func (dec *Decoder) UseNumber() {
+	dec.d.useNumber = true
+}
+
+// DisallowUnknownMembers causes the Decoder to reject any member which is not defined by the specification, other
+// than @-members and members of the extensions with the given namespaces. These would be those negotiated for the
+// request, see MediaTypeRegistry.Namespaces. Decode then returns Errors holding a 400 Bad Request error for each
+// such member, with a source pointer such as "/data/attribute".
+//
+// By default, such members are kept in the Extra field of document and resource objects, and ignored elsewhere.
+func (dec *Decoder) DisallowUnknownMembers(namespaces ...string) {
+	dec.d.namespaces = make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		dec.d.namespaces[ns] = true
+	}
+}
+
+// Strict causes the Decoder to validate the structure of the decoded values against the specification, rather
+// than decode whatever it can, e.g. for request documents sent by clients. Decode then returns Errors holding
+// a 400 Bad Request error with a source pointer for each of the following problems:
+//
+//   - a member which is not defined by the specification, see DisallowUnknownMembers;
+//   - a duplicate member name within an object, including within attributes and meta objects;
+//   - a member of the wrong JSON type, such as "attributes" given as an array;
+//   - a missing member, such as the "type" of a resource object.
+//
+// The primary data of a document and the data of relationships, which are kept as raw JSON, are validated as well.
+func (dec *Decoder) Strict() {
+	dec.d.strict = true
+	if dec.d.namespaces == nil {
+		dec.d.namespaces = make(map[string]bool)
+	}
+}
+
+// Decode reads the next JSON value from its input and stores it in v, which must be one of *Document, *Resource,
+// *[]Resource, *Relationship, *Links, *Link or *Error.
+//
+// Note that the primary data of a document and the data of a relationship are kept as raw JSON, so they should
+// be decoded with a Decoder as well in order to apply the same options.
+func (dec *Decoder) Decode(v interface{}) error {
+	var b json.RawMessage
+	if err := dec.dec.Decode(&b); err != nil {
+		return err
+	}
+
+	// errors are collected on a copy, so they do not carry over to the next call
+	d := dec.d
+	var err error
+	switch typ := v.(type) {
+	case *Document:
+		err = d.document(b, typ, "")
+	case *Resource:
+		err = d.resource(b, typ, "")
+	case *[]Resource:
+		err = d.resources(b, typ, "")
+	case *Relationship:
+		err = d.relationship(b, typ, "")
+	case *Links:
+		err = d.links(b, typ, "")
+	case *Link:
+		err = d.link(b, typ, "")
+	case *Error:
+		err = d.errorObject(b, typ, "")
+	default:
+		return fmt.Errorf("%s: cannot decode into value of type %T", packageName, v)
+	}
+
+	if err == nil && len(d.errs) > 0 {
+		return d.errs
+	}
+	return err
+}
+
+// decoder holds the options used when decoding JSON API values, and knows how to decode each of them member
+// by member so the options apply throughout. Each method takes the JSON pointer of the value it decodes, which
+// is used for the errors collected in strict mode.
+type decoder struct {
+	useNumber bool
+	strict    bool
+
+	// namespaces holds the namespaces of the extensions whose members are allowed if unknown members are
+	// disallowed, i.e. if it is non-nil
+	namespaces map[string]bool
+
+	// identifiers causes the primary data of documents to be validated as resource linkage rather than as
+	// resource objects, as for the documents sent to relationship endpoints
+	identifiers bool
+
+	errs Errors
+}
+
+// member is a member of a JSON object, with its value as raw JSON.
+type member struct {
+	key   string
+	value json.RawMessage
+}
+
+func (d *decoder) document(b []byte, doc *Document, p string) error {
+	ms, err := d.object(b, p)
+	if err != nil || ms == nil {
+		return err
+	}
+
+	temp := Document{}
+	for _, m := range ms {
+		key, v, mp := m.key, m.value, pointerTo(p, m.key)
+		switch key {
+		case "data":
+			if d.expect(v, mp, "an object, an array or null", '{', '[', 'n') {
+				temp.Data = v
+				err = d.primaryData(v, mp)
+			}
+		case "errors":
+			err = d.errors(v, &temp.Errors, mp)
+		case "meta":
+			err = d.free(v, (*map[string]interface{})(&temp.Meta), mp)
+		case "jsonapi":
+			err = d.info(v, &temp.Info, mp)
+		case "links":
+			err = d.links(v, &temp.Links, mp)
+		case "included":
+			err = d.resources(v, &temp.Included, mp)
+		default:
+			d.unknown(key, v, mp, &temp.Extra)
+		}
+		if err = d.check(err, mp); err != nil {
+			return err
+		}
+	}
+
+	if d.strict {
+		switch {
+		case temp.Data == nil && temp.Errors == nil && temp.Meta == nil && len(temp.Extra) == 0:
+			d.fail(p, "Missing member", `document must contain at least one of "data", "errors" or "meta"`)
+		case temp.Data != nil && temp.Errors != nil:
+			d.fail(pointerTo(p, "errors"), "Invalid member", `document must not contain both "data" and "errors"`)
+		}
+	}
+
+	*doc = temp
+	return nil
+}
+
+// primaryData validates the primary data of a document in strict mode, without keeping the result.
+func (d *decoder) primaryData(b []byte, p string) error {
+	if !d.strict {
+		return nil
+	}
+	if d.identifiers {
+		return d.linkage(b, p)
+	}
+	switch jsonKind(b) {
+	case '{':
+		return d.resource(b, &Resource{}, p)
+	case '[':
+		return d.resources(b, &[]Resource{}, p)
+	}
+	return nil
+}
+
+func (d *decoder) info(b []byte, info **Info, p string) error {
+	ms, err := d.object(b, p)
+	if err != nil || ms == nil {
+		return err
+	}
+
+	temp := &Info{}
+	for _, m := range ms {
+		key, v, mp := m.key, m.value, pointerTo(p, m.key)
+		switch key {
+		case "version":
+			err = d.string(v, &temp.Version, mp)
+		case "ext":
+			if d.expect(v, mp, "an array", '[') {
+				err = json.Unmarshal(v, &temp.Ext)
+			}
+		case "profile":
+			if d.expect(v, mp, "an array", '[') {
+				err = json.Unmarshal(v, &temp.Profile)
+			}
+		case "meta":
+			err = d.free(v, (*map[string]interface{})(&temp.Meta), mp)
+		default:
+			d.unknown(key, v, mp, nil)
+		}
+		if err = d.check(err, mp); err != nil {
+			return err
+		}
+	}
+
+	*info = temp
+	return nil
+}
+
+func (d *decoder) resources(b []byte, rs *[]Resource, p string) error {
+	if !d.expect(b, p, "an array", '[', 'n') {
+		return nil
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*rs = nil
+		return nil
+	}
+
+	temp := make([]Resource, len(raw))
+	for i, v := range raw {
+		if err := d.resource(v, &temp[i], pointerTo(p, strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+	*rs = temp
+	return nil
+}
+
+func (d *decoder) resource(b []byte, r *Resource, p string) error {
+	ms, err := d.object(b, p)
+	if err != nil || ms == nil {
+		return err
+	}
+
+	temp := Resource{}
+	hasType := false
+	for _, m := range ms {
+		key, v, mp := m.key, m.value, pointerTo(p, m.key)
+		switch key {
+		case "id":
+			err = d.string(v, &temp.ID, mp)
+		case "lid":
+			err = d.string(v, &temp.Lid, mp)
+		case "type":
+			hasType = true
+			err = d.string(v, &temp.Type, mp)
+		case "attributes":
+			err = d.free(v, (*map[string]interface{})(&temp.Attributes), mp)
+		case "relationships":
+			err = d.relationships(v, &temp.Relationships, mp)
+		case "links":
+			err = d.links(v, &temp.Links, mp)
+		case "meta":
+			err = d.free(v, (*map[string]interface{})(&temp.Meta), mp)
+		default:
+			d.unknown(key, v, mp, &temp.Extra)
+		}
+		if err = d.check(err, mp); err != nil {
+			return err
+		}
+	}
+	if d.strict && !hasType {
+		d.fail(p, "Missing member", `resource object must contain "type"`)
+	}
+
+	*r = temp
+	return nil
+}
+
+func (d *decoder) relationships(b []byte, rs *Relationships, p string) error {
+	ms, err := d.object(b, p)
+	if err != nil || ms == nil {
+		return err
+	}
+
+	temp := make(Relationships, len(ms))
+	for _, m := range ms {
+		key, v, mp := m.key, m.value, pointerTo(p, m.key)
+		if jsonKind(v) == 'n' && !d.strict {
+			temp[key] = nil
+			continue
+		}
+		if !d.expect(v, mp, "an object", '{') {
+			continue
+		}
+		rel := &Relationship{}
+		if err := d.relationship(v, rel, mp); err != nil {
+			return err
+		}
+		temp[key] = rel
+	}
+
+	*rs = temp
+	return nil
+}
+
+func (d *decoder) relationship(b []byte, rel *Relationship, p string) error {
+	ms, err := d.object(b, p)
+	if err != nil || ms == nil {
+		return err
+	}
+
+	temp := Relationship{}
+	for _, m := range ms {
+		key, v, mp := m.key, m.value, pointerTo(p, m.key)
+		switch key {
+		case "links":
+			err = d.links(v, &temp.Links, mp)
+		case "data":
+			if d.expect(v, mp, "an object, an array or null", '{', '[', 'n') {
+				temp.Data = v
+				err = d.linkage(v, mp)
+			}
+		case "meta":
+			err = d.free(v, (*map[string]interface{})(&temp.Meta), mp)
+		default:
+			d.unknown(key, v, mp, nil)
+		}
+		if err = d.check(err, mp); err != nil {
+			return err
+		}
+	}
+	if d.strict && temp.Links == nil && temp.Data == nil && temp.Meta == nil {
+		d.fail(p, "Missing member", `relationship object must contain at least one of "links", "data" or "meta"`)
+	}
+
+	*rel = temp
+	return nil
+}
+
+// linkage validates the data of a relationship in strict mode, without keeping the result.
+func (d *decoder) linkage(b []byte, p string) error {
+	if !d.strict {
+		return nil
+	}
+	switch jsonKind(b) {
+	case '{':
+		return d.identifier(b, p)
+	case '[':
+		var raw []json.RawMessage
+		if err := json.Unmarshal(b, &raw); err != nil {
+			return err
+		}
+		for i, v := range raw {
+			if err := d.identifier(v, pointerTo(p, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// identifier validates a resource identifier object.
+func (d *decoder) identifier(b []byte, p string) error {
+	if !d.expect(b, p, "an object", '{') {
+		return nil
+	}
+	ms, err := d.object(b, p)
+	if err != nil {
+		return err
+	}
+
+	var ri ResourceIdentifier
+	hasType := false
+	for _, m := range ms {
+		key, v, mp := m.key, m.value, pointerTo(p, m.key)
+		switch key {
+		case "type":
+			hasType = true
+			err = d.string(v, &ri.Type, mp)
+		case "id":
+			err = d.string(v, &ri.ID, mp)
+		case "lid":
+			err = d.string(v, &ri.Lid, mp)
+		case "meta":
+			err = d.free(v, (*map[string]interface{})(&ri.Meta), mp)
+		default:
+			d.unknown(key, v, mp, nil)
+		}
+		if err = d.check(err, mp); err != nil {
+			return err
+		}
+	}
+	if !hasType {
+		d.fail(p, "Missing member", `resource identifier object must contain "type"`)
+	}
+	if len(ri.ID) == 0 && len(ri.Lid) == 0 {
+		d.fail(p, "Missing member", `resource identifier object must contain "id" or "lid"`)
+	}
+	return nil
+}
+
+func (d *decoder) links(b []byte, ls *Links, p string) error {
+	ms, err := d.object(b, p)
+	if err != nil || ms == nil {
+		return err
+	}
+
+	temp := make(Links, len(ms))
+	for _, m := range ms {
+		key, v, mp := m.key, m.value, pointerTo(p, m.key)
+		switch jsonKind(v) {
+		case 'n':
+			temp[key] = nil
+
+		case '"':
+			var href string
+			if err := json.Unmarshal(v, &href); err != nil {
+				return err
+			}
+			temp[key] = href
+
+		default:
+			l := &Link{}
+			if err := d.link(v, l, mp); err != nil {
+				return fmt.Errorf("%s: invalid link %q: %v", packageName, key, err)
+			}
+			temp[key] = l
+		}
+	}
+
+	*ls = temp
+	return nil
+}
+
+func (d *decoder) link(b []byte, l *Link, p string) error {
+	switch jsonKind(b) {
+	case '"':
+		var href string
+		if err := json.Unmarshal(b, &href); err != nil {
+			return err
+		}
+		*l = Link{Href: href}
+		return nil
+
+	case '{':
+		// decoded below
+
+	default:
+		if d.strict {
+			d.fail(p, "Invalid member", fmt.Sprintf("value must be a string or an object, got %s", jsonKindName(b)))
+			return nil
+		}
+		return errLinkInvalid
+	}
+
+	ms, err := d.object(b, p)
+	if err != nil {
+		return err
+	}
+
+	temp := Link{}
+	hasHref := false
+	for _, m := range ms {
+		key, v, mp := m.key, m.value, pointerTo(p, m.key)
+		switch key {
+		case "href":
+			hasHref = jsonKind(v) != 'n'
+			err = d.string(v, &temp.Href, mp)
+		case "rel":
+			err = d.string(v, &temp.Rel, mp)
+		case "describedby":
+			if jsonKind(v) != 'n' {
+				temp.DescribedBy = &Link{}
+				err = d.link(v, temp.DescribedBy, mp)
+			}
+		case "title":
+			err = d.string(v, &temp.Title, mp)
+		case "type":
+			err = d.string(v, &temp.Type, mp)
+		case "hreflang":
+			if d.expect(v, mp, "a string or an array", '"', '[') {
+				err = temp.HrefLang.UnmarshalJSON(v)
+			}
+		case "meta":
+			err = d.free(v, (*map[string]interface{})(&temp.Meta), mp)
+		default:
+			d.unknown(key, v, mp, nil)
+		}
+		if err = d.check(err, mp); err != nil {
+			return err
+		}
+	}
+	if !hasHref {
+		if !d.strict {
+			return errLinkMissingHref
+		}
+		d.fail(p, "Missing member", `link object must contain "href"`)
+	}
+
+	*l = temp
+	return nil
+}
+
+func (d *decoder) errors(b []byte, es *Errors, p string) error {
+	if !d.expect(b, p, "an array", '[', 'n') {
+		return nil
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*es = nil
+		return nil
+	}
+
+	temp := make(Errors, len(raw))
+	for i, v := range raw {
+		if err := d.errorObject(v, &temp[i], pointerTo(p, strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+	*es = temp
+	return nil
+}
+
+func (d *decoder) errorObject(b []byte, e *Error, p string) error {
+	ms, err := d.object(b, p)
+	if err != nil || ms == nil {
+		return err
+	}
+
+	temp := Error{}
+	for _, m := range ms {
+		key, v, mp := m.key, m.value, pointerTo(p, m.key)
+		switch key {
+		case "id":
+			err = d.string(v, &temp.ID, mp)
+		case "status":
+			err = d.string(v, &temp.Status, mp)
+		case "code":
+			err = d.string(v, &temp.Code, mp)
+		case "title":
+			err = d.string(v, &temp.Title, mp)
+		case "detail":
+			err = d.string(v, &temp.Detail, mp)
+		case "source":
+			err = d.errorSource(v, &temp.Source, mp)
+		case "links":
+			err = d.links(v, &temp.Links, mp)
+		case "meta":
+			err = d.free(v, (*map[string]interface{})(&temp.Meta), mp)
+		default:
+			d.unknown(key, v, mp, nil)
+		}
+		if err = d.check(err, mp); err != nil {
+			return err
+		}
+	}
+
+	*e = temp
+	return nil
+}
+
+func (d *decoder) errorSource(b []byte, src **ErrorSource, p string) error {
+	ms, err := d.object(b, p)
+	if err != nil || ms == nil {
+		return err
+	}
+
+	temp := &ErrorSource{}
+	for _, m := range ms {
+		key, v, mp := m.key, m.value, pointerTo(p, m.key)
+		switch key {
+		case "pointer":
+			err = d.string(v, &temp.Pointer, mp)
+		case "parameter":
+			err = d.string(v, &temp.Parameter, mp)
+		case "header":
+			err = d.string(v, &temp.Header, mp)
+		default:
+			d.unknown(key, v, mp, nil)
+		}
+		if err = d.check(err, mp); err != nil {
+			return err
+		}
+	}
+
+	*src = temp
+	return nil
+}
+
+// object decodes the members of the JSON object b in order, or returns nil if b is null.
+// In strict mode, a value other than an object or null, as well as any duplicate member, is recorded as an error
+// and skipped.
+func (d *decoder) object(b []byte, p string) ([]member, error) {
+	switch jsonKind(b) {
+	case 'n':
+		return nil, nil
+	case '{':
+		// decoded below
+	default:
+		if d.strict {
+			d.fail(p, "Invalid member", fmt.Sprintf("value must be an object, got %s", jsonKindName(b)))
+			return nil, nil
+		}
+		var ms map[string]json.RawMessage
+		return nil, json.Unmarshal(b, &ms)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	ms := []member{}
+	seen := make(map[string]bool)
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := t.(string)
+
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		if seen[key] && d.strict {
+			d.fail(pointerTo(p, key), "Duplicate member", fmt.Sprintf("member %q must not appear more than once", key))
+			continue
+		}
+		seen[key] = true
+		ms = append(ms, member{key: key, value: v})
+	}
+	return ms, nil
+}
+
+// free decodes a free-form object such as attributes or meta, applying the number option.
+// In strict mode, duplicate members within the object, at any depth, are recorded as errors.
+func (d *decoder) free(b []byte, m *map[string]interface{}, p string) error {
+	if !d.expect(b, p, "an object", '{', 'n') {
+		return nil
+	}
+	if d.strict {
+		d.duplicates(b, p)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if d.useNumber {
+		dec.UseNumber()
+	}
+	return dec.Decode(m)
+}
+
+// duplicates records an error for each duplicate member within b, or any value nested in b.
+func (d *decoder) duplicates(b []byte, p string) {
+	switch jsonKind(b) {
+	case '{':
+		ms, _ := d.object(b, p)
+		for _, m := range ms {
+			d.duplicates(m.value, pointerTo(p, m.key))
+		}
+	case '[':
+		var raw []json.RawMessage
+		if err := json.Unmarshal(b, &raw); err != nil {
+			return
+		}
+		for i, v := range raw {
+			d.duplicates(v, pointerTo(p, strconv.Itoa(i)))
+		}
+	}
+}
+
+// string decodes the JSON string b into s.
+func (d *decoder) string(b []byte, s *string, p string) error {
+	if !d.expect(b, p, "a string", '"') {
+		return nil
+	}
+	return json.Unmarshal(b, s)
+}
+
+// unknown handles a member which is not defined by the specification. If unknown members are disallowed, it
+// records an error unless the member is an @-member or belongs to an allowed extension. Otherwise it keeps the
+// member in ms, if ms is non-nil.
+func (d *decoder) unknown(key string, v json.RawMessage, p string, ms *Members) {
+	if d.namespaces != nil && !IsAtMember(key) {
+		if ns, ok := Namespace(key); !ok || !d.namespaces[ns] {
+			d.fail(p, "Unknown member", fmt.Sprintf("member %q is not allowed", key))
+			return
+		}
+	}
+	if ms == nil {
+		return
+	}
+	if *ms == nil {
+		*ms = make(Members)
+	}
+	(*ms)[key] = v
+}
+
+// expect returns true if b is of one of the given JSON kinds, see jsonKind. In strict mode, it records an error
+// for any other value; otherwise it always returns true, leaving it to encoding/json to report a mismatch.
+func (d *decoder) expect(b []byte, p, want string, kinds ...byte) bool {
+	if !d.strict || bytes.IndexByte(kinds, jsonKind(b)) >= 0 {
+		return true
+	}
+	d.fail(p, "Invalid member", fmt.Sprintf("value must be %s, got %s", want, jsonKindName(b)))
+	return false
+}
+
+// check records err as an error in strict mode, where the JSON types of values have been checked up front,
+// so any remaining error, e.g. from an array with elements of the wrong type, concerns the value at p alone.
+func (d *decoder) check(err error, p string) error {
+	if err == nil || !d.strict {
+		return err
+	}
+	d.fail(p, "Invalid member", err.Error())
+	return nil
+}
+
+// fail records a 400 Bad Request error for the value at p.
+func (d *decoder) fail(p, title, detail string) {
+	e := Error{
+		Status: fmt.Sprint(http.StatusBadRequest),
+		Title:  title,
+		Detail: detail,
+	}
+	if len(p) > 0 {
+		e.Source = &ErrorSource{Pointer: p}
+	}
+	d.errs = append(d.errs, e)
+}
+
+// helpers
+
+// pointerTo returns the JSON pointer to the member or element token of the value at p.
+func pointerTo(p, token string) string {
+	return p + jsonPointer(token)
+}
+
+// jsonKindName returns the name of the JSON type of b, for use in error messages.
+func jsonKindName(b []byte) string {
+	switch jsonKind(b) {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
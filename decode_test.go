@@ -0,0 +1,219 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+var testNumbersJSON = `
+{
+	"data": {
+		"id": "1",
+		"type": "accounts",
+		"attributes": {"externalId": 9007199254740993, "balance": 12345678901234567.89, "total": 18446744073709551615},
+		"relationships": {
+			"owner": {"data": null, "meta": {"count": 9007199254740993}}
+		},
+		"links": {"self": {"href": "http://example.com/accounts/1", "meta": {"version": 9007199254740993}}},
+		"meta": {"revision": 9007199254740993}
+	},
+	"meta": {"total": 9007199254740993},
+	"links": {"self": {"href": "http://example.com/accounts", "meta": {"count": 9007199254740993}}},
+	"included": [{"id": "2", "type": "people", "attributes": {"age": 9007199254740993}}]
+}`
+
+func TestDecoder_Decode_UseNumber(t *testing.T) {
+	const expected = int64(9007199254740993)
+
+	doc := jsonapi.Document{}
+	dec := jsonapi.NewDecoder(strings.NewReader(testNumbersJSON))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatalf("unexpected error when decoding document: %+v", err)
+	}
+
+	r := jsonapi.Resource{}
+	dec = jsonapi.NewDecoder(strings.NewReader(string(doc.Data)))
+	dec.UseNumber()
+	if err := dec.Decode(&r); err != nil {
+		t.Fatalf("unexpected error when decoding primary data: %+v", err)
+	}
+
+	rel, _ := r.Relationships.Get("owner")
+	self, _ := r.Links.Get("self")
+	docSelf, _ := doc.Links.Get("self")
+
+	for name, m := range map[string]jsonapi.Meta{
+		"document meta":          doc.Meta,
+		"document link meta":     docSelf.Meta,
+		"resource meta":          r.Meta,
+		"resource link meta":     self.Meta,
+		"relationship meta":      rel.Meta,
+		"included resource meta": jsonapi.Meta(doc.Included[0].Attributes),
+	} {
+		for key := range m {
+			if actual, ok := m.GetInt64(key); !ok || actual != expected {
+				t.Errorf("%s: expected number to be decoded without loss, expected: %d, actual: %d", name, expected, actual)
+			}
+		}
+	}
+
+	if actual, ok := r.Attributes.Int64("externalId"); !ok || actual != expected {
+		t.Errorf("expected attribute to be decoded without loss, expected: %d, actual: %d", expected, actual)
+	}
+	if actual, ok := r.Attributes.Uint64("total"); !ok || actual != 18446744073709551615 {
+		t.Errorf("unexpected value from Attributes.Uint64: %d", actual)
+	}
+	if actual, ok := r.Attributes.BigInt("total"); !ok || actual.String() != "18446744073709551615" {
+		t.Errorf("unexpected value from Attributes.BigInt: %v", actual)
+	}
+	if actual, ok := r.Attributes.BigRat("balance"); !ok || actual.FloatString(2) != "12345678901234567.89" {
+		t.Errorf("unexpected value from Attributes.BigRat: %v", actual)
+	}
+	if string(rel.Data) != "null" {
+		t.Errorf("expected null relationship data to be kept as raw JSON, actual: %s", rel.Data)
+	}
+}
+
+func TestDecoder_Decode_WithoutUseNumber(t *testing.T) {
+	expected := jsonapi.Document{}
+	if err := json.Unmarshal([]byte(testDocumentJSON), &expected); err != nil {
+		t.Fatalf("unexpected error when unmarshaling document: %+v", err)
+	}
+
+	actual := jsonapi.Document{}
+	if err := jsonapi.NewDecoder(strings.NewReader(testDocumentJSON)).Decode(&actual); err != nil {
+		t.Fatalf("unexpected error when decoding document: %+v", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Decoder.Decode should decode the same document as json.Unmarshal:\n- expected:\t%+v\n- actual:\t%+v",
+			expected, actual)
+	}
+
+	r := jsonapi.Resource{}
+	if err := jsonapi.NewDecoder(strings.NewReader(testArticleJSON)).Decode(&r); err != nil {
+		t.Fatalf("unexpected error when decoding resource: %+v", err)
+	}
+	if v, ok := r.Meta["total"].(float64); !ok || v != 42 {
+		t.Errorf("expected numbers to be decoded as float64 by default, actual: %#v", r.Meta["total"])
+	}
+}
+
+func TestDecoder_Decode_WhenUnsupportedType(t *testing.T) {
+	var v map[string]interface{}
+	if err := jsonapi.NewDecoder(strings.NewReader(`{}`)).Decode(&v); err == nil {
+		t.Error("Decoder.Decode should return an error for unsupported types")
+	}
+}
+
+func TestMeta_GetBigRat(t *testing.T) {
+	m := jsonapi.Meta{"amount": json.Number("0.10"), "float": 0.5, "int": 3}
+	for key, expected := range map[string]*big.Rat{
+		"amount": big.NewRat(1, 10),
+		"float":  big.NewRat(1, 2),
+		"int":    big.NewRat(3, 1),
+	} {
+		if actual, ok := m.GetBigRat(key); !ok || actual.Cmp(expected) != 0 {
+			t.Errorf("unexpected value from Meta.GetBigRat for %s, expected: %v, actual: %v", key, expected, actual)
+		}
+	}
+}
+
+func TestDecoder_Decode_Strict(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		v        interface{}
+		pointers []string
+	}{
+		{"valid document", `{
+			"data": {
+				"type": "articles", "lid": "a1", "@context": "x",
+				"attributes": {"title": "foo"},
+				"relationships": {"author": {"data": {"type": "people", "id": "1"}}}
+			},
+			"jsonapi": {"version": "1.1", "ext": ["https://jsonapi.org/ext/atomic"]}
+		}`, &jsonapi.Document{}, nil},
+		{"misspelled members", `{
+			"data": {"type": "articles", "attribute": {}, "relationship": {}}
+		}`, &jsonapi.Document{}, []string{"/data/attribute", "/data/relationship"}},
+		{"unknown top-level member", `{"data": null, "version:id": "1"}`, &jsonapi.Document{}, []string{"/version:id"}},
+		{"duplicate members", `{
+			"data": {"type": "articles", "type": "people", "attributes": {"tags": [{"a": 1, "a": 2}]}}
+		}`, &jsonapi.Document{}, []string{"/data/type", "/data/attributes/tags/0/a"}},
+		{"wrong types", `{
+			"data": {"id": 1, "type": "articles", "attributes": [], "links": {"self": 42}}
+		}`, &jsonapi.Document{}, []string{"/data/id", "/data/attributes", "/data/links/self"}},
+		{"missing members", `{"data": [{"id": "1"}]}`, &jsonapi.Document{}, []string{"/data/0"}},
+		{"empty document", `{"links": {}}`, &jsonapi.Document{}, []string{""}},
+		{"data and errors", `{"data": null, "errors": []}`, &jsonapi.Document{}, []string{"/errors"}},
+		{"relationship data", `{
+			"data": [{"type": "people", "id": "1", "name": "foo"}, {"id": "2"}, 3]
+		}`, &jsonapi.Relationship{}, []string{"/data/0/name", "/data/1", "/data/2"}},
+		{"empty relationship", `{}`, &jsonapi.Relationship{}, []string{""}},
+		{"link", `{"href": "http://example.com", "hreflang": ["en", 1], "titel": "foo"}`,
+			&jsonapi.Link{}, []string{"/hreflang", "/titel"}},
+		{"link without href", `{"meta": {}}`, &jsonapi.Link{}, []string{""}},
+		{"error", `{"status": 404, "source": {"pointer": "/data", "paramter": "x"}}`,
+			&jsonapi.Error{}, []string{"/status", "/source/paramter"}},
+		{"included", `{"data": null, "included": {}}`, &jsonapi.Document{}, []string{"/included"}},
+	}
+
+	for _, test := range tests {
+		dec := jsonapi.NewDecoder(strings.NewReader(test.json))
+		dec.Strict()
+		err := dec.Decode(test.v)
+
+		if test.pointers == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error from Decoder.Decode in strict mode: %+v", test.name, err)
+			}
+			continue
+		}
+
+		errs, ok := err.(jsonapi.Errors)
+		if !ok || len(errs) != len(test.pointers) {
+			t.Errorf("%s: expected Decoder.Decode to return %d errors, actual: %+v", test.name, len(test.pointers), err)
+			continue
+		}
+		for i, e := range errs {
+			pointer := ""
+			if e.Source != nil {
+				pointer = e.Source.Pointer
+			}
+			if e.Status != "400" || pointer != test.pointers[i] {
+				t.Errorf("%s: unexpected error, expected pointer: %q, actual: %+v", test.name, test.pointers[i], e)
+			}
+		}
+	}
+}
+
+func TestDecoder_Decode_StrictAllowsNegotiatedExtensions(t *testing.T) {
+	dec := jsonapi.NewDecoder(strings.NewReader(`{"atomic:operations": []}`))
+	dec.Strict()
+	dec.DisallowUnknownMembers("atomic")
+
+	doc := jsonapi.Document{}
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatalf("unexpected error from Decoder.Decode: %+v", err)
+	}
+	if _, ok := doc.Extra.Get("atomic:operations"); !ok {
+		t.Errorf("expected member of negotiated extension to be kept, actual: %+v", doc.Extra)
+	}
+}
+
+func TestDecoder_Decode_Error(t *testing.T) {
+	e := jsonapi.Error{}
+	if err := jsonapi.NewDecoder(strings.NewReader(testErrorJSON)).Decode(&e); err != nil {
+		t.Fatalf("unexpected error when decoding error object: %+v", err)
+	}
+	if !reflect.DeepEqual(e, testError) {
+		t.Errorf("unexpected error object, expected: %+v, actual: %+v", testError, e)
+	}
+}
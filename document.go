@@ -4,21 +4,26 @@ import "encoding/json"
 
 // Document represents top-level document at the root of any JSON API request/response containing data.
 //
+// Any other members, such as those of extensions, are kept in Extra when decoding, see Members.
+//
 // http://jsonapi.org/format/#document-top-level
 type Document struct {
-	Data     json.RawMessage        `json:"data,omitempty"`
-	Errors   []Error                `json:"errors,omitempty"`
-	Meta     map[string]interface{} `json:"meta,omitempty"`
-	Info     *Info                  `json:"jsonapi,omitempty"`
-	Links    Links                  `json:"links,omitempty"`
-	Included []Resource             `json:"included,omitempty"`
+	Data     json.RawMessage `json:"data,omitempty"`
+	Errors   Errors          `json:"errors,omitempty"`
+	Meta     Meta            `json:"meta,omitempty"`
+	Info     *Info           `json:"jsonapi,omitempty"`
+	Links    Links           `json:"links,omitempty"`
+	Included []Resource      `json:"included,omitempty"`
+	Extra    Members         `json:"-"`
 }
 
 // Info represents a JSON API Object, used as the "jsonapi" member in the top-level document, which provides
-// information about its implementation.
+// information about its implementation, as well as the extensions and profiles applied to the document.
 //
 // http://jsonapi.org/format/#document-jsonapi-object
 type Info struct {
-	Version string                 `json:"version"`
-	Meta    map[string]interface{} `json:"meta,omitempty"`
+	Version string   `json:"version"`
+	Ext     []string `json:"ext,omitempty"`
+	Profile []string `json:"profile,omitempty"`
+	Meta    Meta     `json:"meta,omitempty"`
 }
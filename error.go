@@ -1,14 +1,74 @@
 package jsonapi
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Error represents a JSON API error object.
 //
+// A *Error also satisfies the error interface, so it can be returned from adapters and other functions
+// to describe the problem with the request in a way that can be sent back to the client as is.
+//
 // http://jsonapi.org/format/#error-objects
 type Error struct {
-	ID     string                 `json:"id,omitempty"`
-	Status string                 `json:"status,omitempty"`
-	Code   string                 `json:"code,omitempty"`
-	Title  string                 `json:"title,omitempty"`
-	Detail string                 `json:"detail,omitempty"`
-	Links  Links                  `json:"links,omitempty"`
-	Meta   map[string]interface{} `json:"meta,omitempty"`
+	ID     string       `json:"id,omitempty"`
+	Status string       `json:"status,omitempty"`
+	Code   string       `json:"code,omitempty"`
+	Title  string       `json:"title,omitempty"`
+	Detail string       `json:"detail,omitempty"`
+	Source *ErrorSource `json:"source,omitempty"`
+	Links  Links        `json:"links,omitempty"`
+	Meta   Meta         `json:"meta,omitempty"`
+}
+
+// ErrorSource represents the "source" member of a JSON API error object, which references the part of the
+// request document, query parameter or header that caused the error.
+//
+// http://jsonapi.org/format/#error-objects
+type ErrorSource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	Header    string `json:"header,omitempty"`
+}
+
+// Error returns a message made up of the status, detail (or title, if there is no detail) and source pointer.
+func (e *Error) Error() string {
+	msg := e.Detail
+	if len(msg) == 0 {
+		msg = e.Title
+	}
+	if len(msg) == 0 {
+		msg = e.Code
+	}
+	if len(e.Status) > 0 {
+		msg = e.Status + " " + msg
+	}
+	if e.Source != nil && len(e.Source.Pointer) > 0 {
+		msg += " (" + e.Source.Pointer + ")"
+	}
+	return fmt.Sprintf("%s: %s", packageName, msg)
+}
+
+// Errors represents a list of JSON API error objects, used as the "errors" member of the top-level document.
+// It satisfies the error interface, so several problems with a request can be reported at once.
+type Errors []Error
+
+// Error returns the messages of all errors, separated by semicolons.
+func (es Errors) Error() string {
+	msgs := make([]string, len(es))
+	for i := range es {
+		msgs[i] = strings.TrimPrefix(es[i].Error(), packageName+": ")
+	}
+	return fmt.Sprintf("%s: %s", packageName, strings.Join(msgs, "; "))
+}
+
+// jsonPointer returns the JSON pointer (RFC 6901) referencing the given path of object keys or array indices.
+func jsonPointer(tokens ...string) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteByte('/')
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(t))
+	}
+	return b.String()
 }
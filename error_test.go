@@ -0,0 +1,33 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+func TestError_Error(t *testing.T) {
+	var err error = &jsonapi.Error{
+		Status: "400",
+		Title:  "Invalid attribute",
+		Detail: "title must be a string",
+		Source: &jsonapi.ErrorSource{Pointer: "/data/attributes/title"},
+	}
+
+	expected := "github.com/smotes/jsonapi: 400 title must be a string (/data/attributes/title)"
+	if actual := err.Error(); actual != expected {
+		t.Errorf("unexpected message from Error.Error, expected: %s, actual: %s", expected, actual)
+	}
+}
+
+func TestErrors_Error(t *testing.T) {
+	var err error = jsonapi.Errors{
+		{Status: "403", Title: "Forbidden"},
+		{Status: "409", Detail: "conflict"},
+	}
+
+	expected := "github.com/smotes/jsonapi: 403 Forbidden; 409 conflict"
+	if actual := err.Error(); actual != expected {
+		t.Errorf("unexpected message from Errors.Error, expected: %s, actual: %s", expected, actual)
+	}
+}
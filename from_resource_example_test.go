@@ -47,16 +47,14 @@ func (a *Article) SetType(typ string) error {
 
 // satisfy attributesWriteAdapter on *Article
 func (a *Article) SetAttributes(as map[string]interface{}) error {
-	if v, ok := as["title"]; ok {
-		if v, ok := v.(string); ok {
-			a.Title = v
-		}
+	attrs := jsonapi.Attributes(as)
+
+	if v, ok := attrs.String("title"); ok {
+		a.Title = v
 	}
 
-	if v, ok := as["body"]; ok {
-		if v, ok := v.(string); ok {
-			a.Body = v
-		}
+	if v, ok := attrs.String("body"); ok {
+		a.Body = v
 	}
 
 	return nil
@@ -1,6 +1,7 @@
 package jsonapi_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/smotes/jsonapi"
@@ -124,3 +125,124 @@ func testFromResourceForError(t *testing.T, adapter interface{}, method string,
 	}
 
 }
+
+// attribute access
+
+type accessWriteAdapter struct {
+	attrs map[string]interface{}
+}
+
+func (a *accessWriteAdapter) SetID(id string) error {
+	return nil
+}
+
+func (a *accessWriteAdapter) SetType(typ string) error {
+	return nil
+}
+
+func (a *accessWriteAdapter) SetAttributes(attrs map[string]interface{}) error {
+	a.attrs = attrs
+	return nil
+}
+
+func (a *accessWriteAdapter) GetAttributeAccess() map[string]jsonapi.AttributeAccess {
+	return map[string]jsonapi.AttributeAccess{
+		"created":  jsonapi.ReadOnly,
+		"slug":     jsonapi.CreateOnly,
+		"password": jsonapi.WriteOnly,
+	}
+}
+
+func TestFromResource_AttributeAccess(t *testing.T) {
+	r := jsonapi.Resource{
+		ID:   "1",
+		Type: "tests",
+		Attributes: jsonapi.Attributes{
+			"title":    "foo",
+			"created":  "2016-01-02T03:04:05Z",
+			"slug":     "foo",
+			"password": "secret",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		opts     []jsonapi.FromResourceOption
+		pointers []string
+	}{
+		{"without intent", nil, nil},
+		{"when creating", []jsonapi.FromResourceOption{jsonapi.ForCreate()}, []string{"/data/attributes/created"}},
+		{"when updating", []jsonapi.FromResourceOption{jsonapi.ForUpdate()}, []string{"/data/attributes/created", "/data/attributes/slug"}},
+	}
+
+	for _, test := range tests {
+		adapter := accessWriteAdapter{}
+		err := jsonapi.FromResource(&adapter, &r, true, test.opts...)
+		if len(test.pointers) == 0 {
+			if err != nil || adapter.attrs == nil {
+				t.Errorf("%s: unexpected error from FromResource: %+v", test.name, err)
+			}
+			continue
+		}
+
+		var errs jsonapi.Errors
+		if !errors.As(err, &errs) || len(errs) != len(test.pointers) {
+			t.Errorf("%s: expected FromResource to return %d errors, actual: %+v", test.name, len(test.pointers), err)
+			continue
+		}
+		for i, e := range errs {
+			if e.Status != "403" || e.Source.Pointer != test.pointers[i] {
+				t.Errorf("%s: unexpected error from FromResource, expected pointer: %s, actual: %+v",
+					test.name, test.pointers[i], e)
+			}
+		}
+		if adapter.attrs != nil {
+			t.Errorf("%s: FromResource should not call SetAttributes when rejecting attributes", test.name)
+		}
+	}
+}
+
+// identity checks
+
+func TestFromResource_ExpectTypeAndID(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource jsonapi.Resource
+		statuses []string
+		pointers []string
+	}{
+		{"matching", jsonapi.Resource{ID: "1", Type: "articles"}, nil, nil},
+		{"conflicting type", jsonapi.Resource{ID: "1", Type: "people"}, []string{"409"}, []string{"/data/type"}},
+		{"conflicting id", jsonapi.Resource{ID: "2", Type: "articles"}, []string{"409"}, []string{"/data/id"}},
+		{"missing id", jsonapi.Resource{Type: "articles"}, []string{"400"}, []string{"/data"}},
+		{"conflicting type and id", jsonapi.Resource{ID: "2", Type: "people"},
+			[]string{"409", "409"}, []string{"/data/type", "/data/id"}},
+	}
+
+	for _, test := range tests {
+		article := Article{}
+		err := jsonapi.FromResource(&article, &test.resource, true,
+			jsonapi.ForUpdate(), jsonapi.ExpectType("articles"), jsonapi.ExpectID("1"))
+		if len(test.statuses) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error from FromResource: %+v", test.name, err)
+			}
+			continue
+		}
+
+		var errs jsonapi.Errors
+		if !errors.As(err, &errs) || len(errs) != len(test.statuses) {
+			t.Errorf("%s: expected FromResource to return %d errors, actual: %+v", test.name, len(test.statuses), err)
+			continue
+		}
+		for i, e := range errs {
+			if e.Status != test.statuses[i] || e.Source.Pointer != test.pointers[i] {
+				t.Errorf("%s: unexpected error from FromResource, expected: %s %s, actual: %+v",
+					test.name, test.statuses[i], test.pointers[i], e)
+			}
+		}
+		if article.ID != 0 {
+			t.Errorf("%s: FromResource should not call SetID when rejecting the resource", test.name)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/smotes/jsonapi
+
+go 1.18
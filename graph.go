@@ -0,0 +1,176 @@
+package jsonapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RelationshipGraph declares the relationships of each resource type, along with the types of the resources they
+// relate to, so the relationship paths of the "include" query parameter can be validated before any resources are
+// loaded. Its zero value is ready to use, and it is safe for concurrent use, provided its limits are either set
+// before it is used or changed with SetLimits.
+type RelationshipGraph struct {
+	// MaxDepth is the maximum number of relationships in an include path, e.g. 2 for "comments.author". If
+	// zero, include paths may be nested without limit.
+	MaxDepth int
+
+	// MaxIncluded is the maximum number of resources an IncludeResolver using the graph includes in a single
+	// document. If zero, any number of resources may be included.
+	MaxIncluded int
+
+	mu    sync.RWMutex
+	types map[string]map[string][]string
+}
+
+// SetLimits sets MaxDepth and MaxIncluded, which is safe while the graph is in use, e.g. by a handler serving
+// requests.
+func (g *RelationshipGraph) SetLimits(maxDepth, maxIncluded int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.MaxDepth, g.MaxIncluded = maxDepth, maxIncluded
+}
+
+// AddType declares the resource type typ, which is useful for types without any relationships.
+func (g *RelationshipGraph) AddType(typ string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.addType(typ)
+}
+
+// Add declares the relationship name of the resource type typ, relating to resources of the target types, which
+// may be several for a polymorphic relationship. Declaring a relationship again adds to its target types.
+func (g *RelationshipGraph) Add(typ, name string, targets ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.addType(typ)
+	for _, target := range targets {
+		if !containsString(g.types[typ][name], target) {
+			g.types[typ][name] = append(g.types[typ][name], target)
+		}
+	}
+	if _, ok := g.types[typ][name]; !ok {
+		g.types[typ][name] = nil
+	}
+}
+
+// Types returns the declared resource types, in alphabetical order.
+func (g *RelationshipGraph) Types() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	types := make([]string, 0, len(g.types))
+	for typ := range g.types {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Relationships returns the names of the relationships declared for the resource type typ, in alphabetical order.
+func (g *RelationshipGraph) Relationships(typ string) []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	names := make([]string, 0, len(g.types[typ]))
+	for name := range g.types[typ] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Targets returns the target types of the relationship name of the resource type typ, and an existence check.
+func (g *RelationshipGraph) Targets(typ, name string) ([]string, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	targets, ok := g.types[typ][name]
+	return append([]string(nil), targets...), ok
+}
+
+// ValidateInclude checks the relationship paths of the "include" query parameter against the relationships
+// declared for the resource type typ of the primary data. Each relationship of a path must be declared for at
+// least one of the types the path reaches up to it, and paths must not be nested deeper than MaxDepth.
+//
+// It returns Errors holding a 400 Bad Request error with the source parameter "include" for each invalid path,
+// as the specification requires for include paths a server does not support.
+func (g *RelationshipGraph) ValidateInclude(typ string, paths []string) error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var errs Errors
+	for _, path := range paths {
+		if err := g.validatePath(typ, path); err != nil {
+			errs = append(errs, *err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (g *RelationshipGraph) validatePath(typ, path string) *Error {
+	names := strings.Split(path, ".")
+	if g.MaxDepth > 0 && len(names) > g.MaxDepth {
+		return includeError(fmt.Sprintf("include path %q is nested deeper than %d relationships", path, g.MaxDepth))
+	}
+
+	types := []string{typ}
+	for _, name := range names {
+		var next []string
+		found := false
+		for _, t := range types {
+			targets, ok := g.types[t][name]
+			if !ok {
+				continue
+			}
+			found = true
+			for _, target := range targets {
+				if !containsString(next, target) {
+					next = append(next, target)
+				}
+			}
+		}
+		if !found {
+			return includeError(fmt.Sprintf("resource type %q has no relationship %q in path %q",
+				strings.Join(types, `" or "`), name, path))
+		}
+		types = next
+	}
+	return nil
+}
+
+// maxIncluded returns MaxIncluded, synchronised with SetLimits.
+func (g *RelationshipGraph) maxIncluded() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.MaxIncluded
+}
+
+func (g *RelationshipGraph) addType(typ string) {
+	if g.types == nil {
+		g.types = make(map[string]map[string][]string)
+	}
+	if g.types[typ] == nil {
+		g.types[typ] = make(map[string][]string)
+	}
+}
+
+// helpers
+
+func includeError(detail string) *Error {
+	return &Error{
+		Status: fmt.Sprint(http.StatusBadRequest),
+		Title:  "Invalid include",
+		Detail: detail,
+		Source: &ErrorSource{Parameter: "include"},
+	}
+}
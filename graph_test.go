@@ -0,0 +1,163 @@
+package jsonapi_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+func newTestGraph() *jsonapi.RelationshipGraph {
+	g := &jsonapi.RelationshipGraph{}
+	g.Add("articles", "author", "people")
+	g.Add("articles", "comments", "comments")
+	g.Add("comments", "author", "people")
+	g.Add("comments", "subject", "articles", "photos")
+	g.Add("photos", "tags", "tags")
+	g.AddType("people")
+	return g
+}
+
+func TestRelationshipGraph_ValidateInclude(t *testing.T) {
+	g := newTestGraph()
+
+	valid := [][]string{
+		nil,
+		{"author"},
+		{"author", "comments.author"},
+		{"comments.subject.tags"},
+		{"comments.subject.comments.author"},
+	}
+	for _, paths := range valid {
+		if err := g.ValidateInclude("articles", paths); err != nil {
+			t.Errorf("%v: unexpected error from ValidateInclude: %+v", paths, err)
+		}
+	}
+
+	tests := []struct {
+		paths []string
+		n     int
+	}{
+		{[]string{"tags"}, 1},
+		{[]string{"author.articles", "comments.author", "comments.foo"}, 2},
+		{[]string{"comments.subject.foo"}, 1},
+	}
+	for _, test := range tests {
+		err := g.ValidateInclude("articles", test.paths)
+		es, ok := err.(jsonapi.Errors)
+		if !ok || len(es) != test.n {
+			t.Errorf("%v: expected %d errors from ValidateInclude, actual: %+v", test.paths, test.n, err)
+			continue
+		}
+		for _, e := range es {
+			if e.Status != "400" || e.Source == nil || e.Source.Parameter != "include" {
+				t.Errorf("%v: unexpected error: %+v", test.paths, e)
+			}
+		}
+	}
+
+	if err := g.ValidateInclude("unknown", []string{"author"}); err == nil {
+		t.Error("expected error for undeclared type")
+	}
+
+	g.MaxDepth = 2
+	if err := g.ValidateInclude("articles", []string{"comments.author"}); err != nil {
+		t.Errorf("unexpected error from ValidateInclude: %+v", err)
+	}
+	if err := g.ValidateInclude("articles", []string{"comments.subject.tags"}); err == nil {
+		t.Error("expected error for include path nested deeper than MaxDepth")
+	}
+}
+
+func TestRelationshipGraph_Declarations(t *testing.T) {
+	g := newTestGraph()
+	g.Add("comments", "subject", "photos", "videos")
+
+	if types := g.Types(); !reflect.DeepEqual(types, []string{"articles", "comments", "people", "photos"}) {
+		t.Errorf("unexpected types: %v", types)
+	}
+	if names := g.Relationships("comments"); !reflect.DeepEqual(names, []string{"author", "subject"}) {
+		t.Errorf("unexpected relationships: %v", names)
+	}
+	if targets, ok := g.Targets("comments", "subject"); !ok || !reflect.DeepEqual(targets, []string{"articles", "photos", "videos"}) {
+		t.Errorf("unexpected targets: %v", targets)
+	}
+	if _, ok := g.Targets("people", "author"); ok {
+		t.Error("expected undeclared relationship not to exist")
+	}
+}
+
+func TestIncludeResolver_Graph(t *testing.T) {
+	g := newTestGraph()
+	loader := &testIncludeLoader{}
+	res := &jsonapi.IncludeResolver{Loader: loader, Graph: g}
+
+	if _, err := res.Resolve(context.Background(), testIncludeArticles(2), []string{"comments.foo"}); err == nil {
+		t.Error("expected error for invalid include path")
+	}
+	if len(loader.batches) > 0 {
+		t.Errorf("expected include paths to be validated before loading, actual batches: %v", loader.batches)
+	}
+
+	g.MaxIncluded = 3
+	_, err := res.Resolve(context.Background(), testIncludeArticles(2), []string{"comments"})
+	if e, ok := err.(*jsonapi.Error); !ok || e.Status != "400" || e.Source.Parameter != "include" {
+		t.Errorf("expected 400 error for too many included resources, actual: %+v", err)
+	}
+}
+
+func TestIncludeResolver_Graph_Polymorphic(t *testing.T) {
+	toOne := func(typ, id string) *jsonapi.Relationship {
+		rel := &jsonapi.Relationship{}
+		rel.SetToOne(&jsonapi.ResourceIdentifier{Type: typ, ID: id})
+		return rel
+	}
+	comments := &jsonapi.Relationship{}
+	comments.SetToMany([]jsonapi.ResourceIdentifier{{Type: "comments", ID: "c1"}, {Type: "comments", ID: "c2"}})
+	rs := []jsonapi.Resource{{
+		Type:          "articles",
+		ID:            "1",
+		Relationships: jsonapi.Relationships{"comments": comments},
+	}}
+
+	// the subject of the first comment is a photo, which has no author, and that of the second an article
+	loader := jsonapi.ResourceLoaderFunc(func(ctx context.Context, typ string, ids []string) ([]jsonapi.Resource, error) {
+		var rs []jsonapi.Resource
+		for _, id := range ids {
+			r := jsonapi.Resource{Type: typ, ID: id}
+			switch id {
+			case "c1":
+				r.Relationships = jsonapi.Relationships{"subject": toOne("photos", "ph1")}
+			case "c2":
+				r.Relationships = jsonapi.Relationships{"subject": toOne("articles", "2")}
+			case "2":
+				r.Relationships = jsonapi.Relationships{"author": toOne("people", "p1")}
+			}
+			rs = append(rs, r)
+		}
+		return rs, nil
+	})
+
+	paths := []string{"comments.subject.author"}
+	g := newTestGraph()
+	if err := g.ValidateInclude("articles", paths); err != nil {
+		t.Fatalf("unexpected error from ValidateInclude: %+v", err)
+	}
+
+	for _, graph := range []*jsonapi.RelationshipGraph{g, nil} {
+		res := &jsonapi.IncludeResolver{Loader: loader, Graph: graph}
+		included, err := res.Resolve(context.Background(), rs, paths)
+		if err != nil {
+			t.Fatalf("unexpected error from Resolve with graph %v: %+v", graph != nil, err)
+		}
+		var keys []string
+		for _, r := range included {
+			keys = append(keys, r.Type+"/"+r.ID)
+		}
+		expected := []string{"comments/c1", "comments/c2", "photos/ph1", "articles/2", "people/p1"}
+		if !reflect.DeepEqual(keys, expected) {
+			t.Errorf("unexpected included resources with graph %v, expected: %v, actual: %v", graph != nil, expected, keys)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package jsonapi
+
+// ResourceIdentifier represents a JSON API resource identifier object, which identifies an individual resource
+// by its "type" and either its "id" or, for resources created within the same request, its "lid".
+//
+// http://jsonapi.org/format/#document-resource-identifier-objects
+type ResourceIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+	Lid  string `json:"lid,omitempty"`
+	Meta Meta   `json:"meta,omitempty"`
+}
+
+// Identifier returns the resource identifier object identifying the resource.
+func (r *Resource) Identifier() ResourceIdentifier {
+	return ResourceIdentifier{
+		Type: r.Type,
+		ID:   r.ID,
+		Lid:  r.Lid,
+	}
+}
@@ -0,0 +1,372 @@
+package jsonapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ResourceLoader loads resources of a single type in batches, e.g. with a single database query per batch.
+//
+// LoadResources returns the resources of type typ with the given IDs, in any order. Resources which do not exist
+// are left out rather than reported as an error.
+type ResourceLoader interface {
+	LoadResources(ctx context.Context, typ string, ids []string) ([]Resource, error)
+}
+
+// ResourceLoaderFunc is an adapter to use an ordinary function as a ResourceLoader.
+type ResourceLoaderFunc func(ctx context.Context, typ string, ids []string) ([]Resource, error)
+
+// LoadResources calls fn(ctx, typ, ids).
+func (fn ResourceLoaderFunc) LoadResources(ctx context.Context, typ string, ids []string) ([]Resource, error) {
+	return fn(ctx, typ, ids)
+}
+
+// IncludeResolver resolves the resources included in a compound document by the relationship paths of the
+// "include" query parameter, such as "comments.author".
+//
+// Rather than looking up the related resources of each resource one by one, it follows the paths level by
+// level: all resource identifiers found in the relationship data of one level are collected, and loaded with a
+// single call to the Loader per type and batch, before moving on to the next level.
+//
+// Loaded resources, as well as those found not to exist, are cached for the lifetime of the resolver, so a new
+// resolver should be used for each request. It is safe for concurrent use.
+type IncludeResolver struct {
+	// Loader loads the related resources.
+	Loader ResourceLoader
+
+	// BatchSize is the maximum number of IDs passed to the Loader at once. If zero, all IDs of a type found on
+	// one level are loaded at once.
+	BatchSize int
+
+	// Concurrency is the maximum number of batches loaded at the same time. If zero, batches are loaded one
+	// after another.
+	Concurrency int
+
+	// Graph, if set, declares the relationships which may be included. The include paths are validated against
+	// it for each type of primary data before any resources are loaded, and its MaxIncluded limit applies.
+	Graph *RelationshipGraph
+
+	mu    sync.Mutex
+	cache map[string]*Resource
+}
+
+// Include resolves the include paths for the primary data of the document doc, and appends the resources
+// included by them to doc.Included, unless they are part of the document already.
+func (res *IncludeResolver) Include(ctx context.Context, doc *Document, paths []string) error {
+	var primary []Resource
+	switch jsonKind(doc.Data) {
+	case '[':
+		if err := json.Unmarshal(doc.Data, &primary); err != nil {
+			return err
+		}
+	case '{':
+		r := Resource{}
+		if err := json.Unmarshal(doc.Data, &r); err != nil {
+			return err
+		}
+		primary = append(primary, r)
+	}
+
+	included, err := res.resolve(ctx, primary, doc.Included, paths)
+	if err != nil {
+		return err
+	}
+	doc.Included = append(doc.Included, included...)
+	return nil
+}
+
+// Resolve follows the include paths from the primary resources rs, and returns the resources included by them,
+// in the order they were found and without any of rs.
+//
+// The relationships along the paths must hold resource linkage, i.e. "data", for their related resources to be
+// included; relationships without it are skipped, as are related resources which do not have the next
+// relationship of a path, e.g. the photos reached by the polymorphic "subject" relationship of "subject.author".
+//
+// Resolve returns a 400 Bad Request *Error with the source parameter "include" for include paths which are not
+// valid for the Graph, and for documents which would include more resources than its MaxIncluded limit. Without
+// a Graph, the same applies if a primary resource does not have the first relationship of a path at all.
+func (res *IncludeResolver) Resolve(ctx context.Context, rs []Resource, paths []string) ([]Resource, error) {
+	return res.resolve(ctx, rs, nil, paths)
+}
+
+// resolve follows the include paths from the primary resources rs, treating the resources of existing as part of
+// the document already.
+func (res *IncludeResolver) resolve(ctx context.Context, rs, existing []Resource, paths []string) ([]Resource, error) {
+	if err := res.validate(rs, paths); err != nil {
+		return nil, err
+	}
+	root := newIncludeTree(paths)
+	maxIncluded := 0
+	if res.Graph != nil {
+		maxIncluded = res.Graph.maxIncluded()
+	}
+
+	// known holds the resources part of the document, either given or included, by type and ID
+	known := make(map[string]*Resource, len(rs)+len(existing))
+	for i := range existing {
+		known[existing[i].Type+"/"+existing[i].ID] = &existing[i]
+	}
+	var frontier []includeStep
+	for i := range rs {
+		known[rs[i].Type+"/"+rs[i].ID] = &rs[i]
+		frontier = append(frontier, includeStep{r: &rs[i], node: root})
+	}
+
+	var (
+		included []Resource
+		visited  = make(map[includeStep]bool)
+	)
+	for len(frontier) > 0 {
+		// collect the resource identifiers of the relationships followed on this level
+		var pending []includeStep
+		missing := make(map[string][]string)
+		var types []string
+		for _, step := range frontier {
+			for _, child := range step.node.children {
+				ris, err := includeIdentifiers(step.r, child, step.node == root && res.Graph == nil)
+				if err != nil {
+					return nil, err
+				}
+				for _, ri := range ris {
+					key := ri.Type + "/" + ri.ID
+					if _, ok := known[key]; !ok && !res.cached(key) {
+						if _, ok := missing[ri.Type]; !ok {
+							types = append(types, ri.Type)
+						}
+						if !containsString(missing[ri.Type], ri.ID) {
+							missing[ri.Type] = append(missing[ri.Type], ri.ID)
+						}
+					}
+					pending = append(pending, includeStep{key: key, node: child})
+				}
+			}
+		}
+
+		if err := res.load(ctx, types, missing); err != nil {
+			return nil, err
+		}
+
+		var next []includeStep
+		for _, step := range pending {
+			r, ok := known[step.key]
+			if !ok {
+				if r = res.lookup(step.key); r == nil {
+					continue
+				}
+				known[step.key] = r
+				included = append(included, *r)
+				if maxIncluded > 0 && len(included) > maxIncluded {
+					return nil, includeError(
+						fmt.Sprintf("include paths would include more than %d resources", maxIncluded))
+				}
+			}
+			step.r = r
+			if len(step.node.children) > 0 && !visited[includeStep{key: step.key, node: step.node}] {
+				visited[includeStep{key: step.key, node: step.node}] = true
+				next = append(next, step)
+			}
+		}
+		frontier = next
+	}
+	return included, nil
+}
+
+// validate validates the include paths against the graph for each type of the primary resources rs.
+func (res *IncludeResolver) validate(rs []Resource, paths []string) error {
+	if res.Graph == nil || len(paths) == 0 {
+		return nil
+	}
+	var types []string
+	for _, r := range rs {
+		if containsString(types, r.Type) {
+			continue
+		}
+		types = append(types, r.Type)
+		if err := res.Graph.ValidateInclude(r.Type, paths); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load loads the missing resources of each type, in batches, and caches them.
+func (res *IncludeResolver) load(ctx context.Context, types []string, missing map[string][]string) error {
+	type batch struct {
+		typ string
+		ids []string
+	}
+	var batches []batch
+	for _, typ := range types {
+		ids := missing[typ]
+		for len(ids) > 0 {
+			n := len(ids)
+			if res.BatchSize > 0 && n > res.BatchSize {
+				n = res.BatchSize
+			}
+			batches = append(batches, batch{typ: typ, ids: ids[:n]})
+			ids = ids[n:]
+		}
+	}
+	if len(batches) == 0 {
+		return nil
+	}
+
+	concurrency := res.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+		sem   = make(chan struct{}, concurrency)
+	)
+	for _, b := range batches {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(b batch) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := res.loadBatch(ctx, b.typ, b.ids); err != nil {
+				once.Do(func() {
+					first = err
+					cancel()
+				})
+			}
+		}(b)
+	}
+	wg.Wait()
+
+	if first != nil {
+		return first
+	}
+	return ctx.Err()
+}
+
+func (res *IncludeResolver) loadBatch(ctx context.Context, typ string, ids []string) error {
+	rs, err := res.Loader.LoadResources(ctx, typ, ids)
+	if err != nil {
+		return err
+	}
+
+	res.mu.Lock()
+	defer res.mu.Unlock()
+
+	if res.cache == nil {
+		res.cache = make(map[string]*Resource)
+	}
+	for _, id := range ids {
+		if _, ok := res.cache[typ+"/"+id]; !ok {
+			res.cache[typ+"/"+id] = nil
+		}
+	}
+	for i := range rs {
+		res.cache[rs[i].Type+"/"+rs[i].ID] = &rs[i]
+	}
+	return nil
+}
+
+func (res *IncludeResolver) cached(key string) bool {
+	res.mu.Lock()
+	defer res.mu.Unlock()
+
+	_, ok := res.cache[key]
+	return ok
+}
+
+func (res *IncludeResolver) lookup(key string) *Resource {
+	res.mu.Lock()
+	defer res.mu.Unlock()
+
+	return res.cache[key]
+}
+
+// includeTree is a node of the tree of relationship paths to include, e.g. the paths "comments.author" and
+// "comments.article" share the node of "comments".
+type includeTree struct {
+	name     string
+	path     string
+	children []*includeTree
+}
+
+func newIncludeTree(paths []string) *includeTree {
+	root := &includeTree{}
+	for _, path := range paths {
+		node := root
+		for i, name := range strings.Split(path, ".") {
+			var child *includeTree
+			for _, c := range node.children {
+				if c.name == name {
+					child = c
+					break
+				}
+			}
+			if child == nil {
+				child = &includeTree{name: name, path: strings.Join(strings.Split(path, ".")[:i+1], ".")}
+				node.children = append(node.children, child)
+			}
+			node = child
+		}
+	}
+	return root
+}
+
+// includeStep is a resource, identified either by pointer or by key, reached by following the include paths up
+// to node.
+type includeStep struct {
+	r    *Resource
+	key  string
+	node *includeTree
+}
+
+// includeIdentifiers returns the resource identifiers of the relationship of r the node refers to. If r does not
+// have the relationship, it returns an error if required is true, and no identifiers otherwise.
+func includeIdentifiers(r *Resource, node *includeTree, required bool) ([]ResourceIdentifier, error) {
+	if !r.HasRelationship(node.name) {
+		if !required {
+			return nil, nil
+		}
+		return nil, includeError(
+			fmt.Sprintf("resource type %q has no relationship %q in path %q", r.Type, node.name, node.path))
+	}
+
+	rel, _ := r.Relationships.Get(node.name)
+	if rel == nil || rel.Data == nil {
+		return nil, nil
+	}
+	if rel.IsToMany() {
+		ris, err := rel.ToMany()
+		return withIDs(ris), err
+	}
+	ri, err := rel.ToOne()
+	if err != nil || ri == nil {
+		return nil, err
+	}
+	return withIDs([]ResourceIdentifier{*ri}), nil
+}
+
+// withIDs returns the resource identifiers which have an ID, leaving out those only identified by a local ID.
+func withIDs(ris []ResourceIdentifier) []ResourceIdentifier {
+	kept := ris[:0]
+	for _, ri := range ris {
+		if len(ri.ID) > 0 {
+			kept = append(kept, ri)
+		}
+	}
+	return kept
+}
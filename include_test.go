@@ -0,0 +1,180 @@
+package jsonapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+// testIncludeLoader serves articles, each with comments written by people, and records each batch it loads.
+type testIncludeLoader struct {
+	mu        sync.Mutex
+	batches   []string
+	active    int
+	maxActive int
+}
+
+func (l *testIncludeLoader) LoadResources(ctx context.Context, typ string, ids []string) ([]jsonapi.Resource, error) {
+	l.mu.Lock()
+	l.batches = append(l.batches, typ+":"+strings.Join(ids, ","))
+	l.active++
+	if l.active > l.maxActive {
+		l.maxActive = l.active
+	}
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.active--
+		l.mu.Unlock()
+	}()
+
+	var rs []jsonapi.Resource
+	for _, id := range ids {
+		switch {
+		case typ == "comments":
+			author := &jsonapi.Relationship{}
+			author.SetToOne(&jsonapi.ResourceIdentifier{Type: "people", ID: "p" + id[len(id)-1:]})
+			rs = append(rs, jsonapi.Resource{
+				Type:          typ,
+				ID:            id,
+				Relationships: jsonapi.Relationships{"author": author},
+			})
+		case typ == "people" && id != "p9":
+			rs = append(rs, jsonapi.Resource{Type: typ, ID: id})
+		}
+	}
+	return rs, nil
+}
+
+func testIncludeArticles(n int) []jsonapi.Resource {
+	rs := make([]jsonapi.Resource, n)
+	for i := range rs {
+		comments := &jsonapi.Relationship{}
+		comments.SetToMany([]jsonapi.ResourceIdentifier{
+			{Type: "comments", ID: fmt.Sprintf("c%d1", i)},
+			{Type: "comments", ID: fmt.Sprintf("c%d2", i)},
+		})
+		author := &jsonapi.Relationship{}
+		author.SetToOne(&jsonapi.ResourceIdentifier{Type: "people", ID: "p1"})
+		rs[i] = jsonapi.Resource{
+			Type:          "articles",
+			ID:            fmt.Sprint(i),
+			Relationships: jsonapi.Relationships{"comments": comments, "author": author},
+		}
+	}
+	return rs
+}
+
+func TestIncludeResolver_Resolve(t *testing.T) {
+	loader := &testIncludeLoader{}
+	res := &jsonapi.IncludeResolver{Loader: loader}
+
+	included, err := res.Resolve(context.Background(), testIncludeArticles(3), []string{"comments.author", "author"})
+	if err != nil {
+		t.Fatalf("unexpected error from Resolve: %+v", err)
+	}
+
+	keys := make([]string, len(included))
+	for i, r := range included {
+		keys[i] = r.Type + "/" + r.ID
+	}
+	expected := []string{
+		"comments/c01", "comments/c02", "people/p1",
+		"comments/c11", "comments/c12", "comments/c21", "comments/c22",
+		"people/p2",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("unexpected included resources, expected: %v, actual: %v", expected, keys)
+	}
+
+	// one batch per type and level, and people already loaded on the first level are not loaded again
+	batches := []string{"comments:c01,c02,c11,c12,c21,c22", "people:p1", "people:p2"}
+	if !reflect.DeepEqual(loader.batches, batches) {
+		t.Errorf("unexpected batches, expected: %v, actual: %v", batches, loader.batches)
+	}
+
+	// resources are cached for the lifetime of the resolver
+	if _, err := res.Resolve(context.Background(), testIncludeArticles(3), []string{"comments.author"}); err != nil {
+		t.Fatalf("unexpected error from Resolve: %+v", err)
+	}
+	if len(loader.batches) != len(batches) {
+		t.Errorf("expected cached resources not to be loaded again, actual batches: %v", loader.batches)
+	}
+}
+
+func TestIncludeResolver_Resolve_Batches(t *testing.T) {
+	loader := &testIncludeLoader{}
+	res := &jsonapi.IncludeResolver{Loader: loader, BatchSize: 3, Concurrency: 2}
+
+	included, err := res.Resolve(context.Background(), testIncludeArticles(10), []string{"comments"})
+	if err != nil {
+		t.Fatalf("unexpected error from Resolve: %+v", err)
+	}
+	if len(included) != 20 {
+		t.Errorf("expected 20 included resources, actual: %d", len(included))
+	}
+	if len(loader.batches) != 7 {
+		t.Errorf("expected 7 batches of at most 3 IDs, actual: %v", loader.batches)
+	}
+	if loader.maxActive > 2 {
+		t.Errorf("expected at most 2 batches loaded at once, actual: %d", loader.maxActive)
+	}
+}
+
+func TestIncludeResolver_Resolve_Missing(t *testing.T) {
+	author := &jsonapi.Relationship{}
+	author.SetToOne(&jsonapi.ResourceIdentifier{Type: "people", ID: "p9"})
+	tags := &jsonapi.Relationship{Links: jsonapi.Links{"related": "/articles/1/tags"}}
+	rs := []jsonapi.Resource{{
+		Type:          "articles",
+		ID:            "1",
+		Relationships: jsonapi.Relationships{"author": author, "tags": tags},
+	}}
+
+	loader := &testIncludeLoader{}
+	res := &jsonapi.IncludeResolver{Loader: loader}
+	included, err := res.Resolve(context.Background(), rs, []string{"author", "tags"})
+	if err != nil {
+		t.Fatalf("unexpected error from Resolve: %+v", err)
+	}
+	if len(included) != 0 {
+		t.Errorf("expected missing resources and relationships without data to be skipped: %+v", included)
+	}
+
+	_, err = res.Resolve(context.Background(), rs, []string{"comments"})
+	if e, ok := err.(*jsonapi.Error); !ok || e.Status != "400" || e.Source.Parameter != "include" {
+		t.Errorf("expected 400 error for unknown relationship, actual: %+v", err)
+	}
+}
+
+func TestIncludeResolver_Include(t *testing.T) {
+	articles := testIncludeArticles(2)
+	b, _ := json.Marshal(articles)
+	doc := &jsonapi.Document{
+		Data:     b,
+		Included: []jsonapi.Resource{{Type: "people", ID: "p1"}},
+	}
+
+	res := &jsonapi.IncludeResolver{Loader: &testIncludeLoader{}}
+	if err := res.Include(context.Background(), doc, []string{"author", "comments"}); err != nil {
+		t.Fatalf("unexpected error from Include: %+v", err)
+	}
+
+	keys := make([]string, len(doc.Included))
+	for i, r := range doc.Included {
+		keys[i] = r.Type + "/" + r.ID
+	}
+	sort.Strings(keys)
+	expected := []string{"comments/c01", "comments/c02", "comments/c11", "comments/c12", "people/p1"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("unexpected included resources, expected: %v, actual: %v", expected, keys)
+	}
+}
@@ -0,0 +1,26 @@
+package jsonschema_test
+
+import (
+	"github.com/smotes/jsonapi"
+)
+
+var testSchema = &jsonapi.ResourceSchema{
+	Type: "articles",
+	Attributes: []jsonapi.AttributeSchema{
+		{Name: "title", Type: jsonapi.StringType},
+		{Name: "summary", Type: jsonapi.StringType, Nullable: true},
+		{Name: "views", Type: jsonapi.IntegerType, Access: jsonapi.ReadOnly},
+		{Name: "slug", Type: jsonapi.StringType, Access: jsonapi.CreateOnly},
+		{Name: "secret", Type: jsonapi.StringType, Access: jsonapi.WriteOnly},
+		{Name: "rating", Type: jsonapi.NumberType},
+		{Name: "pages", Type: jsonapi.IntegerType},
+		{Name: "draft", Type: jsonapi.BooleanType},
+		{Name: "tags", Type: jsonapi.ArrayType},
+		{Name: "extra", Type: jsonapi.ObjectType},
+		{Name: "anything"},
+	},
+	Relationships: []jsonapi.RelationshipSchema{
+		{Name: "author", Types: []string{"people"}},
+		{Name: "comments", ToMany: true, Types: []string{"comments"}},
+	},
+}
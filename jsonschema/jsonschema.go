@@ -0,0 +1,183 @@
+// Package jsonschema exports the resource types registered with a jsonapi.Registry as JSON Schema (draft 2020-12)
+// documents, and validates the attributes of resources sent by clients against the same constraints.
+//
+// Three schemas are exported for each resource type: Resource describes the resource objects returned by the API,
+// while CreateDocument and UpdateDocument describe the documents clients send to create and update a resource.
+// The schemas are self-contained, i.e. they do not reference any other documents, so neither generating nor using
+// them requires network access.
+//
+// Validate checks the attributes of a resource sent by a client without going through JSON Schema:
+//
+//	s, _ := reg.Schema("articles")
+//	if err := jsonschema.Validate(s, r.Attributes, jsonschema.CreatePayload); err != nil {
+//		// err holds jsonapi.Errors with source pointers such as "/data/attributes/title"
+//	}
+package jsonschema // import "github.com/smotes/jsonapi/jsonschema"
+
+import (
+	"github.com/smotes/jsonapi"
+)
+
+// Draft is the URI of the JSON Schema dialect of the exported schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema represents a JSON Schema, ready to be encoded as JSON. Encoding a schema always results in the same
+// output, as the keys of maps are sorted by encoding/json.
+type Schema map[string]interface{}
+
+// Payload represents the kind of document a client sends with a resource.
+type Payload int
+
+const (
+	// CreatePayload is a document sent to create a resource, in which read-only attributes are not allowed.
+	CreatePayload Payload = iota
+
+	// UpdatePayload is a document sent to update a resource, in which read-only and create-only attributes are
+	// not allowed.
+	UpdatePayload
+)
+
+// Resource returns the schema of the resource objects of the type s returned by the API, which hold all
+// attributes but write-only ones.
+func Resource(s *jsonapi.ResourceSchema) Schema {
+	schema := resource(s, nil)
+	schema["$schema"] = Draft
+	schema["title"] = s.Type
+	return schema
+}
+
+// CreateDocument returns the schema of the documents sent by clients to create a resource of the type s.
+func CreateDocument(s *jsonapi.ResourceSchema) Schema {
+	p := CreatePayload
+	return document(s, &p)
+}
+
+// UpdateDocument returns the schema of the documents sent by clients to update a resource of the type s.
+func UpdateDocument(s *jsonapi.ResourceSchema) Schema {
+	p := UpdatePayload
+	return document(s, &p)
+}
+
+// Attribute returns the schema of the values of the attribute a, with the readOnly and writeOnly annotations of
+// its access level.
+func Attribute(a jsonapi.AttributeSchema) Schema {
+	schema := Schema{}
+	if typ := a.Type.String(); len(typ) > 0 {
+		if a.Nullable {
+			schema["type"] = []string{typ, "null"}
+		} else {
+			schema["type"] = typ
+		}
+	}
+	switch a.Access {
+	case jsonapi.ReadOnly:
+		schema["readOnly"] = true
+	case jsonapi.WriteOnly:
+		schema["writeOnly"] = true
+	}
+	return schema
+}
+
+// Allowed returns whether the attribute a is part of the resource objects sent with payload p, or returned by the
+// API if p is nil.
+func Allowed(a jsonapi.AttributeSchema, p *Payload) bool {
+	switch {
+	case p == nil:
+		return a.Access != jsonapi.WriteOnly
+	case a.Access == jsonapi.ReadOnly:
+		return false
+	case a.Access == jsonapi.CreateOnly:
+		return *p == CreatePayload
+	}
+	return true
+}
+
+// Linkage returns the schema of the resource linkage of the relationship rel, whose resource identifiers may hold
+// either an "id" or a local "lid".
+func Linkage(rel jsonapi.RelationshipSchema) Schema {
+	identifier := Schema{
+		"type":     "object",
+		"required": []string{"type"},
+		"anyOf": []Schema{
+			{"required": []string{"id"}},
+			{"required": []string{"lid"}},
+		},
+		"properties": Schema{
+			"type": Schema{"enum": rel.Types},
+			"id":   Schema{"type": "string"},
+			"lid":  Schema{"type": "string"},
+			"meta": Schema{"type": "object"},
+		},
+	}
+	if rel.ToMany {
+		return Schema{"type": "array", "items": identifier}
+	}
+	return Schema{"oneOf": []Schema{identifier, {"type": "null"}}}
+}
+
+func document(s *jsonapi.ResourceSchema, p *Payload) Schema {
+	return Schema{
+		"$schema":  Draft,
+		"title":    s.Type,
+		"type":     "object",
+		"required": []string{"data"},
+		"properties": Schema{
+			"data": resource(s, p),
+			"meta": Schema{"type": "object"},
+		},
+	}
+}
+
+// resource returns the schema of the resource objects of the type s, sent with payload p or returned by the API
+// if p is nil.
+func resource(s *jsonapi.ResourceSchema, p *Payload) Schema {
+	attributes := Schema{}
+	for _, a := range s.Attributes {
+		if Allowed(a, p) {
+			attributes[a.Name] = Attribute(a)
+		}
+	}
+
+	relationships := Schema{}
+	for _, rel := range s.Relationships {
+		properties := Schema{"data": Linkage(rel)}
+		schema := Schema{"type": "object", "properties": properties}
+		if p == nil {
+			properties["links"] = Schema{"type": "object"}
+			properties["meta"] = Schema{"type": "object"}
+		} else {
+			schema["required"] = []string{"data"}
+		}
+		relationships[rel.Name] = schema
+	}
+
+	properties := Schema{
+		"type": Schema{"const": s.Type},
+		"id":   Schema{"type": "string"},
+		"attributes": Schema{
+			"type":                 "object",
+			"properties":           attributes,
+			"additionalProperties": false,
+		},
+		"relationships": Schema{
+			"type":                 "object",
+			"properties":           relationships,
+			"additionalProperties": false,
+		},
+		"meta": Schema{"type": "object"},
+	}
+	required := []string{"type", "id"}
+	switch {
+	case p == nil:
+		properties["links"] = Schema{"type": "object"}
+	case *p == CreatePayload:
+		properties["lid"] = Schema{"type": "string"}
+		required = []string{"type"}
+	}
+
+	return Schema{
+		"type":       "object",
+		"required":   required,
+		"properties": properties,
+	}
+}
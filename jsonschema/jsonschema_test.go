@@ -0,0 +1,111 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/smotes/jsonapi/jsonschema"
+)
+
+// decode encodes and decodes the schema, so it can be inspected as plain JSON values.
+func decode(t *testing.T, s jsonschema.Schema) map[string]interface{} {
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("unexpected error encoding schema: %+v", err)
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatalf("unexpected error decoding schema: %+v", err)
+	}
+	return m
+}
+
+func lookup(v interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+func keys(v interface{}) []string {
+	m, _ := v.(map[string]interface{})
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+func TestResource(t *testing.T) {
+	s := decode(t, jsonschema.Resource(testSchema))
+
+	if s["$schema"] != jsonschema.Draft || s["title"] != "articles" {
+		t.Errorf("unexpected schema header: %v, %v", s["$schema"], s["title"])
+	}
+	if c := lookup(s, "properties", "type", "const"); c != "articles" {
+		t.Errorf("unexpected type constraint: %v", c)
+	}
+
+	attrs := keys(lookup(s, "properties", "attributes", "properties"))
+	expected := []string{"anything", "draft", "extra", "pages", "rating", "slug", "summary", "tags", "title", "views"}
+	if !reflect.DeepEqual(attrs, expected) {
+		t.Errorf("unexpected attributes, expected: %v, actual: %v", expected, attrs)
+	}
+	if v := lookup(s, "properties", "attributes", "properties", "views", "readOnly"); v != true {
+		t.Errorf("expected read-only annotation, actual: %v", v)
+	}
+	if v := lookup(s, "properties", "attributes", "properties", "summary", "type"); !reflect.DeepEqual(v, []interface{}{"string", "null"}) {
+		t.Errorf("unexpected type of nullable attribute: %v", v)
+	}
+	if v := lookup(s, "properties", "attributes", "properties", "anything", "type"); v != nil {
+		t.Errorf("expected attribute of any type to have no type constraint, actual: %v", v)
+	}
+	if v := lookup(s, "properties", "relationships", "properties", "comments", "properties", "data", "type"); v != "array" {
+		t.Errorf("expected to-many linkage to be an array, actual: %v", v)
+	}
+}
+
+func TestCreateDocument(t *testing.T) {
+	s := decode(t, jsonschema.CreateDocument(testSchema))
+
+	data := lookup(s, "properties", "data")
+	if r := lookup(data, "required"); !reflect.DeepEqual(r, []interface{}{"type"}) {
+		t.Errorf("expected only type to be required when creating, actual: %v", r)
+	}
+	attrs := keys(lookup(data, "properties", "attributes", "properties"))
+	expected := []string{"anything", "draft", "extra", "pages", "rating", "secret", "slug", "summary", "tags", "title"}
+	if !reflect.DeepEqual(attrs, expected) {
+		t.Errorf("unexpected attributes, expected: %v, actual: %v", expected, attrs)
+	}
+}
+
+func TestUpdateDocument(t *testing.T) {
+	s := decode(t, jsonschema.UpdateDocument(testSchema))
+
+	data := lookup(s, "properties", "data")
+	if r := lookup(data, "required"); !reflect.DeepEqual(r, []interface{}{"type", "id"}) {
+		t.Errorf("expected type and id to be required when updating, actual: %v", r)
+	}
+	attrs := keys(lookup(data, "properties", "attributes", "properties"))
+	expected := []string{"anything", "draft", "extra", "pages", "rating", "secret", "summary", "tags", "title"}
+	if !reflect.DeepEqual(attrs, expected) {
+		t.Errorf("unexpected attributes, expected: %v, actual: %v", expected, attrs)
+	}
+}
+
+func TestSchema_Stable(t *testing.T) {
+	a, _ := json.Marshal(jsonschema.Resource(testSchema))
+	for i := 0; i < 5; i++ {
+		b, _ := json.Marshal(jsonschema.Resource(testSchema))
+		if string(a) != string(b) {
+			t.Fatal("expected output to be the same across runs")
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/smotes/jsonapi"
+)
+
+// Validate checks the attributes as of a resource of the type s sent by a client with payload p against the
+// constraints of the exported schemas, see CreateDocument and UpdateDocument.
+//
+// It returns jsonapi.Errors holding an error for each invalid attribute, with a source pointer such as
+// "/data/attributes/title": a 400 Bad Request error for attributes which are not declared, are null but neither
+// nullable nor of AnyType, or are of the wrong type, and a 403 Forbidden error for attributes which clients must
+// not send with the payload, consistent with jsonapi.FromResource.
+//
+// Values are expected as decoded by encoding/json, i.e. as string, float64 or json.Number, bool, nil,
+// map[string]interface{} and []interface{}, but other Go values of the corresponding kinds are accepted as well.
+func Validate(s *jsonapi.ResourceSchema, as jsonapi.Attributes, p Payload) error {
+	keys := make([]string, 0, len(as))
+	for key := range as {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs jsonapi.Errors
+	for _, key := range keys {
+		if e := validateAttribute(s, key, as[key], p); e != nil {
+			errs = append(errs, *e)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateAttribute(s *jsonapi.ResourceSchema, key string, v interface{}, p Payload) *jsonapi.Error {
+	a, ok := s.Attribute(key)
+	switch {
+	case !ok:
+		return attributeError(http.StatusBadRequest, "Unknown attribute", key,
+			fmt.Sprintf("resource type %q has no attribute %q", s.Type, key))
+
+	case !Allowed(a, &p):
+		verb := "creating"
+		if p == UpdatePayload {
+			verb = "updating"
+		}
+		return attributeError(http.StatusForbidden, "Forbidden attribute", key,
+			fmt.Sprintf("attribute %q is %s and cannot be set when %s a resource", key, a.Access, verb))
+
+	case v == nil:
+		if !a.Nullable && a.Type != jsonapi.AnyType {
+			return attributeError(http.StatusBadRequest, "Invalid attribute", key,
+				fmt.Sprintf("attribute %q must not be null", key))
+		}
+
+	case !hasType(v, a.Type):
+		return attributeError(http.StatusBadRequest, "Invalid attribute", key,
+			fmt.Sprintf("attribute %q must be of type %s", key, a.Type))
+	}
+	return nil
+}
+
+// hasType returns whether the non-nil value v is of the JSON type t.
+func hasType(v interface{}, t jsonapi.AttributeType) bool {
+	if n, ok := v.(json.Number); ok {
+		switch t {
+		case jsonapi.NumberType:
+			_, err := n.Float64()
+			return err == nil
+		case jsonapi.IntegerType:
+			f, err := n.Float64()
+			return err == nil && f == math.Trunc(f)
+		}
+		return t == jsonapi.AnyType
+	}
+
+	rv := reflect.ValueOf(v)
+	switch t {
+	case jsonapi.StringType:
+		return rv.Kind() == reflect.String
+	case jsonapi.NumberType:
+		return isNumber(rv)
+	case jsonapi.IntegerType:
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return rv.Float() == math.Trunc(rv.Float()) && !math.IsInf(rv.Float(), 0)
+		}
+		return isNumber(rv)
+	case jsonapi.BooleanType:
+		return rv.Kind() == reflect.Bool
+	case jsonapi.ObjectType:
+		return rv.Kind() == reflect.Map || rv.Kind() == reflect.Struct ||
+			(rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Struct)
+	case jsonapi.ArrayType:
+		return rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array
+	}
+	return true
+}
+
+// helpers
+
+func isNumber(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func attributeError(status int, title, key, detail string) *jsonapi.Error {
+	return &jsonapi.Error{
+		Status: fmt.Sprint(status),
+		Title:  title,
+		Detail: detail,
+		Source: &jsonapi.ErrorSource{
+			Pointer: "/data/attributes/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key),
+		},
+	}
+}
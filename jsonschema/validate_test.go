@@ -0,0 +1,75 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+	"github.com/smotes/jsonapi/jsonschema"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []struct {
+		as jsonapi.Attributes
+		p  jsonschema.Payload
+	}{
+		{jsonapi.Attributes{}, jsonschema.CreatePayload},
+		{jsonapi.Attributes{
+			"title":    "foo",
+			"summary":  nil,
+			"slug":     "foo",
+			"secret":   "bar",
+			"rating":   4.5,
+			"pages":    12.0,
+			"draft":    true,
+			"tags":     []interface{}{"a"},
+			"extra":    map[string]interface{}{},
+			"anything": nil,
+		}, jsonschema.CreatePayload},
+		{jsonapi.Attributes{"pages": 12, "rating": json.Number("1.5")}, jsonschema.UpdatePayload},
+	}
+	for _, test := range valid {
+		if err := jsonschema.Validate(testSchema, test.as, test.p); err != nil {
+			t.Errorf("%v: unexpected error from Validate: %+v", test.as, err)
+		}
+	}
+
+	as := jsonapi.Attributes{
+		"title":   nil,
+		"summary": 1.0,
+		"views":   1.0,
+		"slug":    "foo",
+		"pages":   1.5,
+		"draft":   "yes",
+		"tags":    map[string]interface{}{},
+		"extra":   []interface{}{},
+		"a/b":     "c",
+	}
+	err := jsonschema.Validate(testSchema, as, jsonschema.UpdatePayload)
+	es, ok := err.(jsonapi.Errors)
+	if !ok {
+		t.Fatalf("expected Errors from Validate, actual: %+v", err)
+	}
+
+	expected := []struct {
+		pointer, status string
+	}{
+		{"/data/attributes/a~1b", "400"},
+		{"/data/attributes/draft", "400"},
+		{"/data/attributes/extra", "400"},
+		{"/data/attributes/pages", "400"},
+		{"/data/attributes/slug", "403"},
+		{"/data/attributes/summary", "400"},
+		{"/data/attributes/tags", "400"},
+		{"/data/attributes/title", "400"},
+		{"/data/attributes/views", "403"},
+	}
+	if len(es) != len(expected) {
+		t.Fatalf("unexpected errors: %+v", es)
+	}
+	for i, e := range expected {
+		if es[i].Source == nil || es[i].Source.Pointer != e.pointer || es[i].Status != e.status {
+			t.Errorf("unexpected error %d, expected: %s %s, actual: %+v", i, e.status, e.pointer, es[i])
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// LocalIDs resolves the local identifiers ("lid") sent by a client for resources it has not created yet to the IDs
+// assigned by the server, once each resource is created. It is safe for concurrent use, and its zero value is
+// ready to use.
+//
+// https://jsonapi.org/format/1.1/#document-resource-object-identification
+type LocalIDs struct {
+	mu  sync.RWMutex
+	ids map[string]ResourceIdentifier
+}
+
+// Set records id as the server-assigned ID of the resource of the given type with the local identifier lid.
+// It returns an error if lid was already assigned to a different resource.
+func (ls *LocalIDs) Set(typ, lid, id string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if ri, ok := ls.ids[lid]; ok && (ri.Type != typ || ri.ID != id) {
+		return fmt.Errorf("%s: lid %q is already assigned to resource %s/%s", packageName, lid, ri.Type, ri.ID)
+	}
+	if ls.ids == nil {
+		ls.ids = make(map[string]ResourceIdentifier)
+	}
+	ls.ids[lid] = ResourceIdentifier{Type: typ, ID: id, Lid: lid}
+	return nil
+}
+
+// Lookup returns the server-assigned ID of the resource of the given type with the local identifier lid,
+// and an existence check.
+func (ls *LocalIDs) Lookup(typ, lid string) (string, bool) {
+	ls.mu.RLock()
+	defer ls.mu.RUnlock()
+
+	ri, ok := ls.ids[lid]
+	if !ok || ri.Type != typ {
+		return "", false
+	}
+	return ri.ID, true
+}
+
+// ResolveIdentifier sets the ID of the resource identifier from its local identifier, if it has no ID yet.
+// It returns a 400 Bad Request *Error if the local identifier is unknown.
+func (ls *LocalIDs) ResolveIdentifier(ri *ResourceIdentifier) error {
+	if len(ri.ID) > 0 || len(ri.Lid) == 0 {
+		return nil
+	}
+
+	id, ok := ls.Lookup(ri.Type, ri.Lid)
+	if !ok {
+		return &Error{
+			Status: fmt.Sprint(http.StatusBadRequest),
+			Title:  "Unknown lid",
+			Detail: fmt.Sprintf("lid %q does not identify a resource of type %q", ri.Lid, ri.Type),
+		}
+	}
+	ri.ID = id
+	return nil
+}
+
+// ResolveRelationships sets the IDs of all resource identifiers in the relationships' data from their local
+// identifiers, replacing the data in place. Unknown local identifiers are reported as a 400 Bad Request *Error,
+// with a source pointer such as "/data/relationships/author/data".
+func (ls *LocalIDs) ResolveRelationships(rs Relationships) error {
+	return ls.resolveRelationships(rs, "/data/relationships")
+}
+
+func (ls *LocalIDs) resolveRelationships(rs Relationships, pointer string) error {
+	for _, name := range sortedRelationshipNames(rs) {
+		if rel := rs[name]; rel != nil {
+			if err := ls.resolveRelationship(rel, pointer+jsonPointer(name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveRelationship sets the IDs of all resource identifiers in the relationship's data from their local
+// identifiers, replacing the data in place. Unknown local identifiers are reported as a 400 Bad Request *Error,
+// with a source pointer relative to the relationship object, such as "/data/0".
+func (ls *LocalIDs) ResolveRelationship(rel *Relationship) error {
+	return ls.resolveRelationship(rel, "")
+}
+
+func (ls *LocalIDs) resolveRelationship(rel *Relationship, pointer string) error {
+	pointer += "/data"
+
+	if rel.IsToMany() {
+		ris, err := rel.ToMany()
+		if err != nil {
+			return err
+		}
+		for i := range ris {
+			if err := ls.ResolveIdentifier(&ris[i]); err != nil {
+				return withPointer(err, pointer+jsonPointer(strconv.Itoa(i)))
+			}
+		}
+		return rel.SetToMany(ris)
+	}
+
+	ri, err := rel.ToOne()
+	if err != nil || ri == nil {
+		return err
+	}
+	if err := ls.ResolveIdentifier(ri); err != nil {
+		return withPointer(err, pointer)
+	}
+	return rel.SetToOne(ri)
+}
+
+// WithLocalIDs resolves local identifiers using ls: if the resource has no ID but a known local identifier,
+// its server-assigned ID is passed to SetID, and any local identifiers within its relationships' data are
+// resolved before SetRelationships is called. The Resource itself is not modified.
+func WithLocalIDs(ls *LocalIDs) FromResourceOption {
+	return func(o *fromResourceOptions) {
+		o.localIDs = ls
+	}
+}
+
+// resolveLocalIDs returns a copy of r with all local identifiers resolved.
+func (o *fromResourceOptions) resolveLocalIDs(r *Resource) (*Resource, error) {
+	resolved := *r
+	if len(r.ID) == 0 && len(r.Lid) > 0 {
+		if id, ok := o.localIDs.Lookup(r.Type, r.Lid); ok {
+			resolved.ID = id
+		}
+	}
+
+	if r.Relationships != nil {
+		resolved.Relationships = make(Relationships, len(r.Relationships))
+		for name, rel := range r.Relationships {
+			if rel != nil {
+				temp := *rel
+				rel = &temp
+			}
+			resolved.Relationships[name] = rel
+		}
+		if err := o.localIDs.ResolveRelationships(resolved.Relationships); err != nil {
+			return nil, err
+		}
+	}
+	return &resolved, nil
+}
+
+// ValidateLocalIDs checks the local identifiers of all resource objects in the document's primary data and
+// included resources. It returns Errors holding a 400 Bad Request error for each local identifier which is used
+// by more than one resource object, or which is referenced within relationships with a different type.
+func (doc *Document) ValidateLocalIDs() error {
+	type object struct {
+		r       Resource
+		pointer string
+	}
+	var objects []object
+
+	switch jsonKind(doc.Data) {
+	case '[':
+		var rs []Resource
+		if err := json.Unmarshal(doc.Data, &rs); err != nil {
+			return err
+		}
+		for i, r := range rs {
+			objects = append(objects, object{r, jsonPointer("data", strconv.Itoa(i))})
+		}
+	case '{':
+		var r Resource
+		if err := json.Unmarshal(doc.Data, &r); err != nil {
+			return err
+		}
+		objects = append(objects, object{r, "/data"})
+	}
+	for i, r := range doc.Included {
+		objects = append(objects, object{r, jsonPointer("included", strconv.Itoa(i))})
+	}
+
+	var errs Errors
+	declared := make(map[string]string)
+	for _, obj := range objects {
+		if len(obj.r.Lid) == 0 {
+			continue
+		}
+		if _, ok := declared[obj.r.Lid]; ok {
+			errs = append(errs, lidError("Duplicate lid",
+				fmt.Sprintf("lid %q is used by more than one resource object", obj.r.Lid), obj.pointer+"/lid"))
+			continue
+		}
+		declared[obj.r.Lid] = obj.r.Type
+	}
+
+	for _, obj := range objects {
+		for _, name := range sortedRelationshipNames(obj.r.Relationships) {
+			rel := obj.r.Relationships[name]
+			if rel == nil {
+				continue
+			}
+			pointer := obj.pointer + jsonPointer("relationships", name, "data")
+
+			var ris []ResourceIdentifier
+			var pointers []string
+			if rel.IsToMany() {
+				ris, _ = rel.ToMany()
+				for i := range ris {
+					pointers = append(pointers, pointer+jsonPointer(strconv.Itoa(i)))
+				}
+			} else if ri, _ := rel.ToOne(); ri != nil {
+				ris, pointers = []ResourceIdentifier{*ri}, []string{pointer}
+			}
+
+			for i, ri := range ris {
+				if typ, ok := declared[ri.Lid]; ok && len(ri.Lid) > 0 && typ != ri.Type {
+					errs = append(errs, lidError("Conflicting lid",
+						fmt.Sprintf("lid %q identifies a resource of type %q, not %q", ri.Lid, typ, ri.Type),
+						pointers[i]+"/type"))
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func lidError(title, detail, pointer string) Error {
+	return Error{
+		Status: fmt.Sprint(http.StatusBadRequest),
+		Title:  title,
+		Detail: detail,
+		Source: &ErrorSource{Pointer: pointer},
+	}
+}
+
+// withPointer sets the source pointer of err if it is a *Error without one.
+func withPointer(err error, pointer string) error {
+	if e, ok := err.(*Error); ok && e.Source == nil {
+		e.Source = &ErrorSource{Pointer: pointer}
+	}
+	return err
+}
+
+func sortedRelationshipNames(rs Relationships) []string {
+	names := make([]string, 0, len(rs))
+	for name := range rs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,135 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+func TestLocalIDs(t *testing.T) {
+	ls := jsonapi.LocalIDs{}
+	if err := ls.Set("people", "p1", "42"); err != nil {
+		t.Fatalf("unexpected error from LocalIDs.Set: %+v", err)
+	}
+	if err := ls.Set("people", "p1", "42"); err != nil {
+		t.Errorf("LocalIDs.Set should allow setting the same ID again, actual: %+v", err)
+	}
+	if err := ls.Set("people", "p1", "43"); err == nil {
+		t.Error("LocalIDs.Set should return an error when the lid is assigned to a different resource")
+	}
+
+	if id, ok := ls.Lookup("people", "p1"); !ok || id != "42" {
+		t.Errorf("unexpected result from LocalIDs.Lookup: %s, %v", id, ok)
+	}
+	if _, ok := ls.Lookup("articles", "p1"); ok {
+		t.Error("LocalIDs.Lookup should return false for a different type")
+	}
+
+	ri := jsonapi.ResourceIdentifier{Type: "people", Lid: "p1"}
+	if err := ls.ResolveIdentifier(&ri); err != nil || ri.ID != "42" {
+		t.Errorf("unexpected result from LocalIDs.ResolveIdentifier: %+v, %v", ri, err)
+	}
+	ri = jsonapi.ResourceIdentifier{Type: "people", Lid: "unknown"}
+	if err := ls.ResolveIdentifier(&ri); err == nil {
+		t.Error("LocalIDs.ResolveIdentifier should return an error for unknown lids")
+	}
+}
+
+func TestFromResource_WithLocalIDs(t *testing.T) {
+	ls := &jsonapi.LocalIDs{}
+	ls.Set("articles", "a1", "1")
+	ls.Set("people", "p1", "42")
+
+	r := jsonapi.Resource{}
+	if err := json.Unmarshal([]byte(`
+	{
+		"lid": "a1",
+		"type": "articles",
+		"relationships": {
+			"author": {"data": {"type": "people", "lid": "p1"}},
+			"comments": {"data": [{"type": "comments", "id": "5"}]}
+		}
+	}`), &r); err != nil {
+		t.Fatalf("unexpected error when unmarshaling resource: %+v", err)
+	}
+
+	adapter := relationshipsWriteAdapter{}
+	if err := jsonapi.FromResource(&adapter, &r, true, jsonapi.WithLocalIDs(ls)); err != nil {
+		t.Fatalf("unexpected error from FromResource: %+v", err)
+	}
+	if adapter.id != "1" {
+		t.Errorf("expected FromResource to resolve the resource's lid, actual id: %s", adapter.id)
+	}
+	rel, _ := adapter.rels.Get("author")
+	if ri, err := rel.ToOne(); err != nil || ri.ID != "42" {
+		t.Errorf("expected FromResource to resolve lids in relationships, actual: %+v, %v", ri, err)
+	}
+	if rel, _ := r.Relationships.Get("author"); rel.Data == nil || string(rel.Data) != `{"type": "people", "lid": "p1"}` {
+		t.Errorf("FromResource should not modify the resource when resolving lids, actual: %s", rel.Data)
+	}
+
+	r.Relationships["author"].Data = json.RawMessage(`{"type": "people", "lid": "unknown"}`)
+	var e *jsonapi.Error
+	if err := jsonapi.FromResource(&adapter, &r, true, jsonapi.WithLocalIDs(ls)); !errors.As(err, &e) ||
+		e.Source.Pointer != "/data/relationships/author/data" {
+		t.Errorf("expected error with source pointer for unknown lid, actual: %+v", err)
+	}
+}
+
+func TestDocument_ValidateLocalIDs(t *testing.T) {
+	doc := jsonapi.Document{}
+	if err := json.Unmarshal([]byte(`
+	{
+		"data": [
+			{"type": "articles", "lid": "a1", "relationships": {"author": {"data": {"type": "people", "lid": "p1"}}}},
+			{"type": "articles", "lid": "a1"}
+		],
+		"included": [
+			{"type": "people", "lid": "p1"},
+			{"type": "comments", "lid": "c1", "relationships": {"article": {"data": {"type": "people", "lid": "a1"}}}}
+		]
+	}`), &doc); err != nil {
+		t.Fatalf("unexpected error when unmarshaling document: %+v", err)
+	}
+
+	var errs jsonapi.Errors
+	if err := doc.ValidateLocalIDs(); !errors.As(err, &errs) {
+		t.Fatalf("expected Document.ValidateLocalIDs to return Errors, actual: %+v", err)
+	}
+
+	expected := []string{"/data/1/lid", "/included/1/relationships/article/data/type"}
+	if len(errs) != len(expected) {
+		t.Fatalf("unexpected number of errors from Document.ValidateLocalIDs: %+v", errs)
+	}
+	for i, e := range errs {
+		if e.Status != "400" || e.Source.Pointer != expected[i] {
+			t.Errorf("unexpected error from Document.ValidateLocalIDs, expected pointer: %s, actual: %+v", expected[i], e)
+		}
+	}
+
+	valid := jsonapi.Document{Data: json.RawMessage(`{"type": "articles", "lid": "a1"}`)}
+	if err := valid.ValidateLocalIDs(); err != nil {
+		t.Errorf("unexpected error from Document.ValidateLocalIDs: %+v", err)
+	}
+}
+
+type relationshipsWriteAdapter struct {
+	id   string
+	rels jsonapi.Relationships
+}
+
+func (a *relationshipsWriteAdapter) SetID(id string) error {
+	a.id = id
+	return nil
+}
+
+func (a *relationshipsWriteAdapter) SetType(typ string) error {
+	return nil
+}
+
+func (a *relationshipsWriteAdapter) SetRelationships(rels jsonapi.Relationships) error {
+	a.rels = rels
+	return nil
+}
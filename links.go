@@ -1,16 +1,79 @@
 package jsonapi
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
 // Links represents a JSON API links object, which can include JSON API link objects or strings representing links.
 //
+// When unmarshaled from JSON, each value of the links object is decoded into either a string, a *Link or nil
+// for null links.
+//
 // http://jsonapi.org/format/#document-links
 type Links map[string]interface{}
 
-// Link represents a JSON API link object, which includes the required "href" key and optional "meta" key.
+// Link represents a JSON API link object, which includes the required "href" key and the optional "rel",
+// "describedby", "title", "type", "hreflang" and "meta" keys.
 //
 // http://jsonapi.org/format/#document-links
+//
+// https://jsonapi.org/format/1.1/#auto-id--link-objects
 type Link struct {
-	Href string                 `json:"href"`
-	Meta map[string]interface{} `json:"meta,omitempty"`
+	Href        string   `json:"href"`
+	Rel         string   `json:"rel,omitempty"`
+	DescribedBy *Link    `json:"describedby,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	HrefLang    HrefLang `json:"hreflang,omitempty"`
+	Meta        Meta     `json:"meta,omitempty"`
+}
+
+// HrefLang represents the "hreflang" member of a JSON API link object, indicating the language(s) of the link's
+// target. It is encoded as a string when it holds a single language tag, and as an array of strings otherwise.
+type HrefLang []string
+
+// MarshalJSON encodes a single language tag as a string, and any other number of tags as an array.
+func (hl HrefLang) MarshalJSON() ([]byte, error) {
+	if len(hl) == 1 {
+		return json.Marshal(hl[0])
+	}
+	return json.Marshal([]string(hl))
+}
+
+// UnmarshalJSON decodes either a string or an array of strings.
+func (hl *HrefLang) UnmarshalJSON(b []byte) error {
+	var tag string
+	if err := json.Unmarshal(b, &tag); err == nil {
+		*hl = HrefLang{tag}
+		return nil
+	}
+	var tags []string
+	if err := json.Unmarshal(b, &tags); err != nil {
+		return err
+	}
+	*hl = tags
+	return nil
+}
+
+// UnmarshalJSON decodes either a link object or a string, which is used as the link's href. The latter allows
+// members such as "describedby", whose value may be either, to be decoded as a Link.
+//
+// It returns an error if the link object is missing the required "href" member, or if the value is neither
+// a string nor an object.
+func (l *Link) UnmarshalJSON(b []byte) error {
+	d := decoder{}
+	return d.link(b, l, "")
+}
+
+// UnmarshalJSON decodes each value of the links object into either a string, a *Link or nil, so no further
+// conversion is required when calling GetString or Get.
+//
+// It returns an error if any value is not a string, link object or null, or is a link object missing "href".
+func (ls *Links) UnmarshalJSON(b []byte) error {
+	d := decoder{}
+	return d.links(b, ls, "")
 }
 
 // AddString adds the string key, value pair to the meta object.
@@ -47,8 +110,37 @@ func (ls Links) GetString(key string) (string, bool) {
 	}
 }
 
+// AddNull adds the key with a null value to the links object, indicating that the link is not available.
+// It overwrites any existing values associated with key.
+func (ls Links) AddNull(key string) {
+	if ls == nil {
+		return
+	}
+	ls[key] = nil
+}
+
+// IsNull returns true if the key exists and its value is null.
+func (ls Links) IsNull(key string) bool {
+	v, ok := ls.getKey(key)
+	if !ok {
+		return false
+	}
+
+	switch typ := v.(type) {
+	case nil:
+		return true
+	case *Link:
+		return typ == nil
+	default:
+		return false
+	}
+}
+
 // Get returns the object value associated with the given key and an existence check.
 // Returns a nil/false if the value associated with the key does not exist or does not reference a Link struct.
+//
+// Values of type map[string]interface{}, e.g. when the links object was built by hand from generic JSON,
+// are converted to a Link on each call.
 func (ls Links) Get(key string) (*Link, bool) {
 	v, ok := ls.getKey(key)
 	if !ok {
@@ -57,20 +149,12 @@ func (ls Links) Get(key string) (*Link, bool) {
 
 	switch typ := v.(type) {
 	case map[string]interface{}:
-		l := Link{}
-		if href, ok := typ["href"]; ok {
-			if href, ok := href.(string); ok {
-				l.Href = href
-			}
-		}
-		if meta, ok := typ["meta"]; ok {
-			if meta, ok := meta.(map[string]interface{}); ok {
-				l.Meta = meta
-			}
-		}
-		return &l, true
+		return linkFromMap(typ), true
 
 	case *Link:
+		if typ == nil {
+			return nil, false
+		}
 		return typ, true
 
 	default:
@@ -93,3 +177,60 @@ func (ls Links) getKey(key string) (v interface{}, ok bool) {
 	v, ok = ls[key]
 	return
 }
+
+// linkFromMap converts the generic map produced by JSON unmarshaling into a Link.
+func linkFromMap(m map[string]interface{}) *Link {
+	l := Link{}
+	if href, ok := m["href"].(string); ok {
+		l.Href = href
+	}
+	if rel, ok := m["rel"].(string); ok {
+		l.Rel = rel
+	}
+	switch describedBy := m["describedby"].(type) {
+	case string:
+		l.DescribedBy = &Link{Href: describedBy}
+	case map[string]interface{}:
+		l.DescribedBy = linkFromMap(describedBy)
+	}
+	if title, ok := m["title"].(string); ok {
+		l.Title = title
+	}
+	if typ, ok := m["type"].(string); ok {
+		l.Type = typ
+	}
+	switch hrefLang := m["hreflang"].(type) {
+	case string:
+		l.HrefLang = HrefLang{hrefLang}
+	case []interface{}:
+		for _, tag := range hrefLang {
+			if tag, ok := tag.(string); ok {
+				l.HrefLang = append(l.HrefLang, tag)
+			}
+		}
+	}
+	switch meta := m["meta"].(type) {
+	case map[string]interface{}:
+		l.Meta = meta
+	case Meta:
+		l.Meta = meta
+	}
+	return &l
+}
+
+// jsonKind returns the first byte of the JSON value b, identifying its kind: '{' for objects, '[' for arrays,
+// '"' for strings, 'n' for null, 't' or 'f' for booleans and '-' or a digit for numbers. It returns 0 for empty input.
+func jsonKind(b []byte) byte {
+	b = bytes.TrimLeft(b, " \t\r\n")
+	if len(b) == 0 {
+		return 0
+	}
+	return b[0]
+}
+
+// errors
+
+var (
+	errLinkMissingHref = fmt.Errorf("%s: link object is missing required member \"href\"", packageName)
+	errLinkInvalid     = fmt.Errorf("%s: link must be a string or a link object", packageName)
+)
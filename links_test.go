@@ -1,6 +1,7 @@
 package jsonapi_test
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
 
@@ -51,7 +52,7 @@ func TestLinks_Add(t *testing.T) {
 	} else if actual, ok := v.(*jsonapi.Link); !ok {
 		t.Error("unexpected value type after Links.Add, expected *Link")
 	} else if actual != expected {
-		t.Errorf("unexpected value after Links.Add, expected: %s, actual: %s", expected, actual)
+		t.Errorf("unexpected value after Links.Add, expected: %v, actual: %v", expected, actual)
 	}
 }
 
@@ -116,7 +117,7 @@ func TestLinks_Get(t *testing.T) {
 	if actual, ok := ls.Get(key); !ok {
 		t.Error("expected Links.Get to return true existence check after Links.Add for the given key")
 	} else if actual != expected {
-		t.Errorf("unexpected value after Links.Get, expected: %s, actual: %s",
+		t.Errorf("unexpected value after Links.Get, expected: %v, actual: %v",
 			expected, actual)
 	}
 }
@@ -183,3 +184,131 @@ func TestLinks_Delete(t *testing.T) {
 		t.Error("Links.Get should return nil/false after calling Links.Delete for the given key")
 	}
 }
+
+func TestLinks_Get_FromMapWithLinkObjectMembers(t *testing.T) {
+	var (
+		ls       = jsonapi.Links{}
+		key      = "foo"
+		expected = &jsonapi.Link{
+			Href:        "http://foo.com",
+			Rel:         "alternate",
+			DescribedBy: &jsonapi.Link{Href: "http://foo.com/schema"},
+			Title:       "Foo",
+			Type:        "text/html",
+			HrefLang:    jsonapi.HrefLang{"en", "de"},
+		}
+	)
+
+	ls[key] = map[string]interface{}{
+		"href":        expected.Href,
+		"rel":         expected.Rel,
+		"describedby": expected.DescribedBy.Href,
+		"title":       expected.Title,
+		"type":        expected.Type,
+		"hreflang":    []interface{}{"en", "de"},
+	}
+	if actual, ok := ls.Get(key); !ok {
+		t.Error("expected Links.Get to return *Links/true for underlying map[string]interface{} value")
+	} else if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected Links.Get to return correct *Links value, expected: %+v, actual: %+v", expected, actual)
+	}
+}
+
+func TestLinks_AddNull_WhenNil(t *testing.T) {
+	var ls jsonapi.Links = nil
+	defer catchPanic(t, "Links", "AddNull")
+	ls.AddNull("foo")
+}
+
+func TestLinks_IsNull(t *testing.T) {
+	ls := jsonapi.Links{}
+	ls.AddNull("null")
+	ls.Add("nullLink", nil)
+	ls.AddString("string", "")
+
+	if !ls.IsNull("null") || !ls.IsNull("nullLink") {
+		t.Error("Links.IsNull should return true for keys with null values")
+	}
+	if ls.IsNull("string") || ls.IsNull("missing") {
+		t.Error("Links.IsNull should return false for keys with non-null values or no values")
+	}
+	if v, ok := ls.Get("null"); v != nil || ok {
+		t.Error("Links.Get should return nil/false for keys with null values")
+	}
+}
+
+func TestLinks_JSONRoundTrip(t *testing.T) {
+	expected := `
+	{
+		"self": "http://foo.com",
+		"next": null,
+		"about": {
+			"href": "http://foo.com/about",
+			"rel": "help",
+			"describedby": {"href": "http://foo.com/about.schema.json"},
+			"title": "About",
+			"type": "text/html",
+			"hreflang": "en"
+		},
+		"translations": {
+			"href": "http://foo.com/translations",
+			"hreflang": ["en", "de"]
+		}
+	}`
+
+	ls := jsonapi.Links{}
+	if err := json.Unmarshal([]byte(expected), &ls); err != nil {
+		t.Fatalf("unexpected error when unmarshaling links: %+v", err)
+	}
+	if !ls.IsNull("next") {
+		t.Error("Links.IsNull should return true for null link values after unmarshaling")
+	}
+
+	b, err := json.Marshal(ls)
+	if err != nil {
+		t.Fatalf("unexpected error when marshaling links: %+v", err)
+	}
+	if err := compareJSON(expected, string(b)); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
+
+func TestLink_UnmarshalJSON_DescribedByString(t *testing.T) {
+	l := jsonapi.Link{}
+	if err := json.Unmarshal([]byte(`{"href": "http://foo.com", "describedby": "http://foo.com/schema"}`), &l); err != nil {
+		t.Fatalf("unexpected error when unmarshaling link: %+v", err)
+	}
+	if l.DescribedBy == nil || l.DescribedBy.Href != "http://foo.com/schema" {
+		t.Errorf("expected describedby string to be decoded as link href, actual: %+v", l.DescribedBy)
+	}
+}
+
+func TestLinks_UnmarshalJSON(t *testing.T) {
+	ls := jsonapi.Links{}
+	if err := json.Unmarshal([]byte(testLinksJSON), &ls); err != nil {
+		t.Fatalf("unexpected error when unmarshaling links: %+v", err)
+	}
+
+	if v, ok := ls["test"].(string); !ok || v != testHref {
+		t.Errorf("expected string link to be decoded as string, actual: %#v", ls["test"])
+	}
+	if v, ok := ls["test2"].(*jsonapi.Link); !ok || v.Href != testHref {
+		t.Errorf("expected link object to be decoded as *Link, actual: %#v", ls["test2"])
+	}
+}
+
+func TestLinks_UnmarshalJSON_WhenInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{"self": {"meta": {"foo": "bar"}}}`,
+		`{"self": {"href": 42}}`,
+		`{"self": 42}`,
+		`{"self": ["http://foo.com"]}`,
+		`{"self": {"href": "http://foo.com", "describedby": true}}`,
+		`["http://foo.com"]`,
+	} {
+		ls := jsonapi.Links{}
+		if err := json.Unmarshal([]byte(data), &ls); err == nil {
+			t.Errorf("expected error when unmarshaling invalid links: %s", data)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package jsonapi
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Extension describes a JSON API extension, identified by its URI. All members an extension adds to a
+// document are prefixed with its namespace, e.g. "atomic:operations".
+//
+// https://jsonapi.org/format/1.1/#extensions
+type Extension struct {
+	URI       string
+	Namespace string
+}
+
+// Profile describes a JSON API profile, identified by its URI.
+//
+// https://jsonapi.org/format/1.1/#profiles
+type Profile struct {
+	URI string
+}
+
+// MediaTypeParams holds the extensions and profiles applied to a JSON API document, as given by the "ext" and
+// "profile" parameters of its media type.
+type MediaTypeParams struct {
+	Ext     []string
+	Profile []string
+}
+
+// ParseMediaType parses a JSON API media type, such as the value of a Content-Type header.
+// It returns an error if the media type is not the JSON API media type, or if it has any parameters other than
+// "ext" and "profile", which the JSON API specification does not allow.
+func ParseMediaType(s string) (MediaTypeParams, error) {
+	var p MediaTypeParams
+
+	typ, params, err := mime.ParseMediaType(s)
+	if err != nil {
+		return p, err
+	}
+	if typ != MediaType {
+		return p, fmt.Errorf("%s: unexpected media type %q", packageName, typ)
+	}
+
+	for name, v := range params {
+		switch name {
+		case "ext":
+			p.Ext = strings.Fields(v)
+		case "profile":
+			p.Profile = strings.Fields(v)
+		default:
+			return p, fmt.Errorf("%s: unexpected media type parameter %q", packageName, name)
+		}
+	}
+	return p, nil
+}
+
+// String returns the JSON API media type with the "ext" and "profile" parameters, if any, suitable for use as
+// the Content-Type header of a response.
+func (p MediaTypeParams) String() string {
+	s := MediaType
+	if len(p.Ext) > 0 {
+		s += `;ext="` + strings.Join(p.Ext, " ") + `"`
+	}
+	if len(p.Profile) > 0 {
+		s += `;profile="` + strings.Join(p.Profile, " ") + `"`
+	}
+	return s
+}
+
+// Info returns a JSON API object for the given version, listing the extensions and profiles.
+func (p MediaTypeParams) Info(version string) *Info {
+	return &Info{Version: version, Ext: p.Ext, Profile: p.Profile}
+}
+
+// HasExt returns true if the extension uri is applied.
+func (p MediaTypeParams) HasExt(uri string) bool {
+	return containsString(p.Ext, uri)
+}
+
+// HasProfile returns true if the profile uri is applied.
+func (p MediaTypeParams) HasProfile(uri string) bool {
+	return containsString(p.Profile, uri)
+}
+
+// MediaTypeRegistry holds the extensions and profiles supported by a server, and negotiates which of them apply
+// to a request and its response. It is safe for concurrent use, and its zero value is ready to use, supporting
+// no extensions and no profiles.
+type MediaTypeRegistry struct {
+	mu         sync.RWMutex
+	extensions map[string]Extension
+	profiles   map[string]Profile
+}
+
+// RegisterExtension adds the extension e to the registry.
+// It returns an error if e has no URI or namespace, or if its URI or namespace is already registered.
+func (reg *MediaTypeRegistry) RegisterExtension(e Extension) error {
+	if len(e.URI) == 0 || len(e.Namespace) == 0 {
+		return errExtensionInvalid
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	for _, other := range reg.extensions {
+		if other.URI == e.URI || other.Namespace == e.Namespace {
+			return fmt.Errorf("%s: extension %q conflicts with registered extension %q", packageName, e.URI, other.URI)
+		}
+	}
+	if reg.extensions == nil {
+		reg.extensions = make(map[string]Extension)
+	}
+	reg.extensions[e.URI] = e
+	return nil
+}
+
+// RegisterProfile adds the profile p to the registry.
+// It returns an error if p has no URI, or if its URI is already registered.
+func (reg *MediaTypeRegistry) RegisterProfile(p Profile) error {
+	if len(p.URI) == 0 {
+		return errProfileInvalid
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if _, ok := reg.profiles[p.URI]; ok {
+		return fmt.Errorf("%s: profile %q is already registered", packageName, p.URI)
+	}
+	if reg.profiles == nil {
+		reg.profiles = make(map[string]Profile)
+	}
+	reg.profiles[p.URI] = p
+	return nil
+}
+
+// Extension returns the registered extension with the given URI and an existence check.
+func (reg *MediaTypeRegistry) Extension(uri string) (Extension, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	e, ok := reg.extensions[uri]
+	return e, ok
+}
+
+// ExtensionByNamespace returns the registered extension with the given namespace and an existence check.
+// It can be used to tell whether a namespaced member such as "atomic:operations" belongs to a known extension.
+func (reg *MediaTypeRegistry) ExtensionByNamespace(namespace string) (Extension, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	for _, e := range reg.extensions {
+		if e.Namespace == namespace {
+			return e, true
+		}
+	}
+	return Extension{}, false
+}
+
+// Namespaces returns the namespaces of the registered extensions with the given URIs, e.g. to allow their members
+// with Decoder.DisallowUnknownMembers. Extensions which are not registered are skipped.
+func (reg *MediaTypeRegistry) Namespaces(uris []string) []string {
+	var namespaces []string
+	for _, uri := range uris {
+		if e, ok := reg.Extension(uri); ok {
+			namespaces = append(namespaces, e.Namespace)
+		}
+	}
+	return namespaces
+}
+
+// Profile returns the registered profile with the given URI and an existence check.
+func (reg *MediaTypeRegistry) Profile(uri string) (Profile, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	p, ok := reg.profiles[uri]
+	return p, ok
+}
+
+// ContentType negotiates the extensions and profiles of a request document from its Content-Type header.
+//
+// It returns a 415 Unsupported Media Type *Error if the header is not the JSON API media type, has any parameters
+// other than "ext" and "profile", or lists an extension which is not registered. Profiles which are not
+// registered are ignored, as the specification allows, and are left out of the result.
+func (reg *MediaTypeRegistry) ContentType(header string) (MediaTypeParams, error) {
+	p, err := ParseMediaType(header)
+	if err != nil {
+		return p, mediaTypeError(http.StatusUnsupportedMediaType, "Content-Type",
+			fmt.Sprintf(`media type must be %q, with no parameters other than "ext" and "profile"`, MediaType))
+	}
+	for _, uri := range p.Ext {
+		if _, ok := reg.Extension(uri); !ok {
+			return p, mediaTypeError(http.StatusUnsupportedMediaType, "Content-Type",
+				fmt.Sprintf("extension %q is not supported", uri))
+		}
+	}
+	p.Profile = reg.supportedProfiles(p.Profile)
+	return p, nil
+}
+
+// Accept negotiates the extensions and profiles of a response document from the Accept header of a request.
+//
+// Instances of the JSON API media type with parameters other than "ext" and "profile" are ignored, and so are
+// instances listing an extension which is not registered. Of the remaining instances, the one with the highest
+// quality wins. If there are instances of the JSON API media type but none remain, Accept returns a 406 Not
+// Acceptable *Error. If there are none at all, e.g. because the header is empty or only accepts "*/*", Accept
+// returns no extensions and no profiles.
+func (reg *MediaTypeRegistry) Accept(header string) (MediaTypeParams, error) {
+	type candidate struct {
+		params  MediaTypeParams
+		quality float64
+	}
+
+	var (
+		found      bool
+		candidates []candidate
+	)
+	for _, elem := range splitQuoted(header, ',') {
+		typ, params, err := mime.ParseMediaType(elem)
+		if err != nil || typ != MediaType {
+			continue
+		}
+		found = true
+
+		// the quality is an accept parameter rather than a media type parameter
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil || quality == 0 {
+				continue
+			}
+			delete(params, "q")
+		}
+
+		var mt strings.Builder
+		mt.WriteString(MediaType)
+		for name, v := range params {
+			mt.WriteString(";" + name + "=" + strconv.Quote(v))
+		}
+		p, err := ParseMediaType(mt.String())
+		if err != nil || !reg.supportsExtensions(p.Ext) {
+			continue
+		}
+		p.Profile = reg.supportedProfiles(p.Profile)
+		candidates = append(candidates, candidate{params: p, quality: quality})
+	}
+
+	if !found {
+		return MediaTypeParams{}, nil
+	}
+	if len(candidates) == 0 {
+		return MediaTypeParams{}, mediaTypeError(http.StatusNotAcceptable, "Accept",
+			"none of the accepted JSON API media types are supported")
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].params, nil
+}
+
+// Negotiate negotiates the extensions and profiles of both the request and response documents of r, see
+// ContentType and Accept. The Content-Type header is only checked if the request has a body.
+//
+// The extensions applied to the response must be both supported and accepted by the client, so if the request
+// document applies an extension the client does not accept, Negotiate returns a 406 Not Acceptable *Error.
+func (reg *MediaTypeRegistry) Negotiate(r *http.Request) (req, res MediaTypeParams, err error) {
+	if r.ContentLength != 0 && r.Body != nil && r.Body != http.NoBody {
+		if req, err = reg.ContentType(r.Header.Get("Content-Type")); err != nil {
+			return req, res, err
+		}
+	}
+
+	accept := strings.Join(r.Header.Values("Accept"), ",")
+	if res, err = reg.Accept(accept); err != nil {
+		return req, res, err
+	}
+
+	// responses to requests without any JSON API instances in Accept apply the request's extensions
+	if len(res.Ext) == 0 && len(req.Ext) > 0 && !acceptsMediaType(accept) {
+		res.Ext = req.Ext
+	}
+	for _, uri := range req.Ext {
+		if !res.HasExt(uri) {
+			return req, res, mediaTypeError(http.StatusNotAcceptable, "Accept",
+				fmt.Sprintf("extension %q of the request is not accepted", uri))
+		}
+	}
+	return req, res, nil
+}
+
+func (reg *MediaTypeRegistry) supportsExtensions(uris []string) bool {
+	for _, uri := range uris {
+		if _, ok := reg.Extension(uri); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (reg *MediaTypeRegistry) supportedProfiles(uris []string) []string {
+	var supported []string
+	for _, uri := range uris {
+		if _, ok := reg.Profile(uri); ok {
+			supported = append(supported, uri)
+		}
+	}
+	return supported
+}
+
+// helpers
+
+// acceptsMediaType returns true if the Accept header lists the JSON API media type.
+func acceptsMediaType(accept string) bool {
+	for _, elem := range splitQuoted(accept, ',') {
+		if typ, _, err := mime.ParseMediaType(elem); err == nil && typ == MediaType {
+			return true
+		}
+	}
+	return false
+}
+
+func mediaTypeError(status int, header, detail string) *Error {
+	return &Error{
+		Status: fmt.Sprint(status),
+		Title:  http.StatusText(status),
+		Detail: detail,
+		Source: &ErrorSource{Header: header},
+	}
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// errors
+
+var (
+	errExtensionInvalid = fmt.Errorf("%s: extension must have a URI and a namespace", packageName)
+	errProfileInvalid   = fmt.Errorf("%s: profile must have a URI", packageName)
+)
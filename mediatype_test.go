@@ -0,0 +1,197 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+const (
+	testExtURI     = "https://jsonapi.org/ext/atomic"
+	testProfileURI = "http://example.com/profiles/timestamps"
+)
+
+func newTestMediaTypeRegistry(t *testing.T) *jsonapi.MediaTypeRegistry {
+	reg := &jsonapi.MediaTypeRegistry{}
+	if err := reg.RegisterExtension(jsonapi.Extension{URI: testExtURI, Namespace: "atomic"}); err != nil {
+		t.Fatalf("unexpected error when registering extension: %+v", err)
+	}
+	if err := reg.RegisterProfile(jsonapi.Profile{URI: testProfileURI}); err != nil {
+		t.Fatalf("unexpected error when registering profile: %+v", err)
+	}
+	return reg
+}
+
+func TestParseMediaType(t *testing.T) {
+	p, err := jsonapi.ParseMediaType(`application/vnd.api+json; ext="a b"; profile="c"`)
+	if err != nil {
+		t.Fatalf("unexpected error from ParseMediaType: %+v", err)
+	}
+	expected := jsonapi.MediaTypeParams{Ext: []string{"a", "b"}, Profile: []string{"c"}}
+	if !reflect.DeepEqual(p, expected) {
+		t.Errorf("unexpected parameters, expected: %+v, actual: %+v", expected, p)
+	}
+	if s := p.String(); s != `application/vnd.api+json;ext="a b";profile="c"` {
+		t.Errorf("unexpected media type string: %s", s)
+	}
+	if !p.HasExt("b") || p.HasExt("c") || !p.HasProfile("c") {
+		t.Errorf("unexpected result from HasExt/HasProfile for %+v", p)
+	}
+
+	for _, s := range []string{"application/json", "application/vnd.api+json; charset=utf-8", ""} {
+		if _, err := jsonapi.ParseMediaType(s); err == nil {
+			t.Errorf("expected error from ParseMediaType for %q", s)
+		}
+	}
+}
+
+func TestMediaTypeRegistry_Register(t *testing.T) {
+	reg := newTestMediaTypeRegistry(t)
+
+	if err := reg.RegisterExtension(jsonapi.Extension{URI: "http://example.com/ext", Namespace: "atomic"}); err == nil {
+		t.Error("expected error when registering extension with duplicate namespace")
+	}
+	if err := reg.RegisterExtension(jsonapi.Extension{URI: "http://example.com/ext"}); err == nil {
+		t.Error("expected error when registering extension without namespace")
+	}
+	if err := reg.RegisterProfile(jsonapi.Profile{URI: testProfileURI}); err == nil {
+		t.Error("expected error when registering duplicate profile")
+	}
+
+	if e, ok := reg.ExtensionByNamespace("atomic"); !ok || e.URI != testExtURI {
+		t.Errorf("unexpected result from ExtensionByNamespace: %+v, %v", e, ok)
+	}
+	if _, ok := reg.Extension("http://example.com/ext"); ok {
+		t.Error("expected Extension to return false for unregistered extension")
+	}
+	if _, ok := reg.Profile(testProfileURI); !ok {
+		t.Error("expected Profile to return true for registered profile")
+	}
+}
+
+func TestMediaTypeRegistry_ContentType(t *testing.T) {
+	reg := newTestMediaTypeRegistry(t)
+
+	p, err := reg.ContentType(`application/vnd.api+json;ext="` + testExtURI + `";profile="` + testProfileURI + ` http://example.com/other"`)
+	if err != nil {
+		t.Fatalf("unexpected error from ContentType: %+v", err)
+	}
+	expected := jsonapi.MediaTypeParams{Ext: []string{testExtURI}, Profile: []string{testProfileURI}}
+	if !reflect.DeepEqual(p, expected) {
+		t.Errorf("unexpected parameters, expected: %+v, actual: %+v", expected, p)
+	}
+
+	for _, header := range []string{
+		`application/vnd.api+json;ext="http://example.com/ext"`,
+		`application/vnd.api+json;charset=utf-8`,
+		`text/plain`,
+	} {
+		_, err := reg.ContentType(header)
+		var e *jsonapi.Error
+		if !errors.As(err, &e) || e.Status != "415" || e.Source.Header != "Content-Type" {
+			t.Errorf("expected 415 error for Content-Type %q, actual: %+v", header, err)
+		}
+		if e != nil && strings.Contains(e.Detail, "github.com/smotes/jsonapi") {
+			t.Errorf("expected client-facing detail for Content-Type %q, actual: %q", header, e.Detail)
+		}
+	}
+}
+
+func TestMediaTypeRegistry_Accept(t *testing.T) {
+	reg := newTestMediaTypeRegistry(t)
+
+	tests := []struct {
+		header   string
+		expected []string
+		status   string
+	}{
+		{"", nil, ""},
+		{"*/*", nil, ""},
+		{"application/vnd.api+json", nil, ""},
+		{`application/vnd.api+json;ext="` + testExtURI + `", application/vnd.api+json;q=0.5`, []string{testExtURI}, ""},
+		{`application/vnd.api+json;q=0.5, application/vnd.api+json;ext="` + testExtURI + `"`, []string{testExtURI}, ""},
+		{`application/vnd.api+json;ext="http://example.com/ext", application/vnd.api+json`, nil, ""},
+		{`application/vnd.api+json;ext="http://example.com/ext"`, nil, "406"},
+		{`application/vnd.api+json;charset=utf-8, */*`, nil, "406"},
+	}
+
+	for _, test := range tests {
+		p, err := reg.Accept(test.header)
+		if len(test.status) > 0 {
+			var e *jsonapi.Error
+			if !errors.As(err, &e) || e.Status != test.status {
+				t.Errorf("expected %s error for Accept %q, actual: %+v", test.status, test.header, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for Accept %q: %+v", test.header, err)
+			continue
+		}
+		if !reflect.DeepEqual(p.Ext, test.expected) {
+			t.Errorf("unexpected extensions for Accept %q, expected: %v, actual: %v", test.header, test.expected, p.Ext)
+		}
+	}
+}
+
+func TestMediaTypeRegistry_Negotiate(t *testing.T) {
+	reg := newTestMediaTypeRegistry(t)
+	atomicMediaType := `application/vnd.api+json;ext="` + testExtURI + `"`
+
+	r := httptest.NewRequest(http.MethodPost, "/operations", strings.NewReader("{}"))
+	r.Header.Set("Content-Type", atomicMediaType)
+	r.Header.Set("Accept", atomicMediaType)
+
+	req, res, err := reg.Negotiate(r)
+	if err != nil {
+		t.Fatalf("unexpected error from Negotiate: %+v", err)
+	}
+	if !req.HasExt(testExtURI) || res.String() != atomicMediaType {
+		t.Errorf("unexpected result from Negotiate: %+v, %+v", req, res)
+	}
+
+	r.Header.Set("Accept", "*/*")
+	if _, res, err = reg.Negotiate(r); err != nil || !res.HasExt(testExtURI) {
+		t.Errorf("expected extension of request to apply to response accepting any media type: %+v, %+v", res, err)
+	}
+
+	r.Header.Set("Accept", jsonapi.MediaType)
+	var e *jsonapi.Error
+	if _, _, err = reg.Negotiate(r); !errors.As(err, &e) || e.Status != "406" {
+		t.Errorf("expected 406 error when extension of request is not accepted, actual: %+v", err)
+	}
+
+	// the Content-Type header of requests without a body is not checked
+	r = httptest.NewRequest(http.MethodGet, "/articles", nil)
+	r.Header.Set("Content-Type", "text/plain")
+	if _, _, err = reg.Negotiate(r); err != nil {
+		t.Errorf("unexpected error from Negotiate for request without body: %+v", err)
+	}
+}
+
+func TestInfo_ExtAndProfile(t *testing.T) {
+	info := jsonapi.MediaTypeParams{Ext: []string{testExtURI}, Profile: []string{testProfileURI}}.Info("1.1")
+
+	b, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("unexpected error when marshaling info: %+v", err)
+	}
+	expected := `{"version":"1.1","ext":["` + testExtURI + `"],"profile":["` + testProfileURI + `"]}`
+	if string(b) != expected {
+		t.Errorf("unexpected JSON for info, expected: %s, actual: %s", expected, b)
+	}
+
+	doc := jsonapi.Document{}
+	if err := jsonapi.NewDecoder(strings.NewReader(`{"meta": {}, "jsonapi": ` + expected + `}`)).Decode(&doc); err != nil {
+		t.Fatalf("unexpected error when decoding document: %+v", err)
+	}
+	if !reflect.DeepEqual(doc.Info, info) {
+		t.Errorf("unexpected decoded info, expected: %+v, actual: %+v", info, doc.Info)
+	}
+}
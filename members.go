@@ -0,0 +1,152 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Members holds the members of a JSON API object which are not defined by the specification, as raw JSON: members
+// of extensions, whose names are prefixed with the extension's namespace such as "atomic:operations", @-members
+// such as "@context", and any other unknown members.
+//
+// Documents and resources keep such members in their Extra field when decoded, and write them back out when
+// encoded, so they survive a round trip, e.g. when proxying documents from other services.
+type Members map[string]json.RawMessage
+
+// Add adds the key, value pair to the members, encoding v as JSON.
+// It overwrites any existing values associated with key.
+func (ms Members) Add(key string, v interface{}) error {
+	if ms == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	ms[key] = b
+	return nil
+}
+
+// Get returns the raw JSON value associated with the given key and an existence check.
+func (ms Members) Get(key string) (json.RawMessage, bool) {
+	if ms == nil {
+		return nil, false
+	}
+	v, ok := ms[key]
+	return v, ok
+}
+
+// Decode decodes the value associated with the given key into v, and returns whether the key exists.
+func (ms Members) Decode(key string, v interface{}) (bool, error) {
+	b, ok := ms.Get(key)
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(b, v)
+}
+
+// Delete deletes the value associated with the given key.
+func (ms Members) Delete(key string) {
+	if ms == nil {
+		return
+	}
+	delete(ms, key)
+}
+
+// Namespace returns the namespace of the extension member name, e.g. "atomic" for "atomic:operations",
+// and an existence check. Names of @-members and other members have no namespace.
+func Namespace(name string) (string, bool) {
+	i := strings.IndexByte(name, ':')
+	if i <= 0 || strings.HasPrefix(name, "@") {
+		return "", false
+	}
+	return name[:i], true
+}
+
+// IsAtMember returns true if name is the name of an @-member, which processors of JSON API documents must ignore.
+//
+// https://jsonapi.org/format/1.1/#document-member-names-at-members
+func IsAtMember(name string) bool {
+	return strings.HasPrefix(name, "@")
+}
+
+// MarshalJSON encodes the document, including its extra members.
+func (doc Document) MarshalJSON() ([]byte, error) {
+	type document Document
+	b, err := json.Marshal(document(doc))
+	if err != nil {
+		return nil, err
+	}
+	return doc.Extra.appendTo(b, documentMembers)
+}
+
+// UnmarshalJSON decodes the document, keeping any members not defined by the specification in Extra.
+func (doc *Document) UnmarshalJSON(b []byte) error {
+	d := decoder{}
+	return d.document(append([]byte(nil), b...), doc, "")
+}
+
+// MarshalJSON encodes the resource, including its extra members.
+func (r Resource) MarshalJSON() ([]byte, error) {
+	type resource Resource
+	b, err := json.Marshal(resource(r))
+	if err != nil {
+		return nil, err
+	}
+	return r.Extra.appendTo(b, resourceMembers)
+}
+
+// UnmarshalJSON decodes the resource, keeping any members not defined by the specification in Extra.
+func (r *Resource) UnmarshalJSON(b []byte) error {
+	d := decoder{}
+	return d.resource(append([]byte(nil), b...), r, "")
+}
+
+// appendTo appends the members to the encoded object b in a stable order, skipping the members the
+// specification defines for the object so they cannot be overridden.
+func (ms Members) appendTo(b []byte, defined map[string]bool) ([]byte, error) {
+	keys := make([]string, 0, len(ms))
+	for key := range ms {
+		if !defined[key] {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return b, nil
+	}
+	sort.Strings(keys)
+
+	buf := bytes.NewBuffer(b[:len(b)-1])
+	for i, key := range keys {
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(ms[key])
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 || len(b) > 2 {
+			buf.WriteByte(',')
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// helpers
+
+// the members the specification defines for each object
+var (
+	documentMembers = map[string]bool{
+		"data": true, "errors": true, "meta": true, "jsonapi": true, "links": true, "included": true,
+	}
+	resourceMembers = map[string]bool{
+		"id": true, "lid": true, "type": true, "attributes": true, "relationships": true, "links": true, "meta": true,
+	}
+)
@@ -0,0 +1,107 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+const testExtraMembersJSON = `{"data":{"id":"1","type":"articles","@context":"http://schema.org","version:id":"v2"},"atomic:operations":[]}`
+
+func TestDocument_ExtraMembers_RoundTrip(t *testing.T) {
+	doc := jsonapi.Document{}
+	if err := json.Unmarshal([]byte(testExtraMembersJSON), &doc); err != nil {
+		t.Fatalf("unexpected error when unmarshaling document: %+v", err)
+	}
+	if v, ok := doc.Extra.Get("atomic:operations"); !ok || string(v) != "[]" {
+		t.Errorf("expected extension member to be kept in document, actual: %+v", doc.Extra)
+	}
+
+	r := jsonapi.Resource{}
+	if err := json.Unmarshal(doc.Data, &r); err != nil {
+		t.Fatalf("unexpected error when unmarshaling resource: %+v", err)
+	}
+	var version string
+	if ok, err := r.Extra.Decode("version:id", &version); !ok || err != nil || version != "v2" {
+		t.Errorf("expected extension member to be kept in resource, actual: %+v", r.Extra)
+	}
+	if _, ok := r.Extra.Get("@context"); !ok {
+		t.Errorf("expected @-member to be kept in resource, actual: %+v", r.Extra)
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("unexpected error when marshaling document: %+v", err)
+	}
+	expected := `{"data":{"id":"1","type":"articles","@context":"http://schema.org","version:id":"v2"},"atomic:operations":[]}`
+	if string(b) != expected {
+		t.Errorf("unexpected JSON for document, expected: %s, actual: %s", expected, b)
+	}
+}
+
+func TestResource_MarshalJSON_ExtraMembers(t *testing.T) {
+	r := jsonapi.Resource{Type: "articles", Extra: jsonapi.Members{}}
+	r.Extra.Add("type", "people")
+	r.Extra.Add("version:id", 2)
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("unexpected error when marshaling resource: %+v", err)
+	}
+	expected := `{"type":"articles","version:id":2}`
+	if string(b) != expected {
+		t.Errorf("unexpected JSON for resource, expected: %s, actual: %s", expected, b)
+	}
+
+	b, err = json.Marshal(jsonapi.Document{Extra: jsonapi.Members{"atomic:results": json.RawMessage(`[ ]`)}})
+	if err != nil || string(b) != `{"atomic:results":[]}` {
+		t.Errorf("unexpected JSON for empty document with extra members: %s, %+v", b, err)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	tests := []struct {
+		name, namespace string
+		ok              bool
+	}{
+		{"atomic:operations", "atomic", true},
+		{"@context", "", false},
+		{":foo", "", false},
+		{"data", "", false},
+	}
+	for _, test := range tests {
+		if ns, ok := jsonapi.Namespace(test.name); ns != test.namespace || ok != test.ok {
+			t.Errorf("unexpected namespace of %q, expected: %q/%v, actual: %q/%v", test.name, test.namespace, test.ok, ns, ok)
+		}
+	}
+}
+
+func TestDecoder_DisallowUnknownMembers(t *testing.T) {
+	tests := []struct {
+		json string
+		ok   bool
+	}{
+		{`{"atomic:operations": [], "@context": {}}`, true},
+		{`{"data": {"id": "1", "type": "articles", "atomic:lid": "x"}}`, true},
+		{`{"version:id": "1"}`, false},
+		{`{"data": {"id": "1", "type": "articles", "attribute": {}}}`, false},
+	}
+
+	for _, test := range tests {
+		dec := jsonapi.NewDecoder(strings.NewReader(test.json))
+		dec.DisallowUnknownMembers("atomic")
+
+		doc := jsonapi.Document{}
+		err := dec.Decode(&doc)
+		if err == nil && len(doc.Data) > 0 {
+			dec = jsonapi.NewDecoder(strings.NewReader(string(doc.Data)))
+			dec.DisallowUnknownMembers("atomic")
+			err = dec.Decode(&jsonapi.Resource{})
+		}
+		if (err == nil) != test.ok {
+			t.Errorf("unexpected result when decoding %s with unknown members disallowed: %+v", test.json, err)
+		}
+	}
+}
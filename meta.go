@@ -0,0 +1,120 @@
+package jsonapi
+
+import (
+	"math/big"
+	"time"
+)
+
+// Meta represents a JSON API meta object, which can include any non-standard meta-information.
+//
+// Values decoded from JSON are typically of type float64 or json.Number for numbers, depending on the decoder,
+// so the typed getters accept either along with any of Go's numeric types.
+//
+// http://jsonapi.org/format/#document-meta
+type Meta map[string]interface{}
+
+// Add adds the key, value pair to the meta object.
+// It overwrites any existing values associated with key.
+func (m Meta) Add(key string, v interface{}) {
+	if m == nil {
+		return
+	}
+	m[key] = v
+}
+
+// Get returns the value associated with the given key and an existence check.
+func (m Meta) Get(key string) (interface{}, bool) {
+	if m == nil {
+		return nil, false
+	}
+	v, ok := m[key]
+	return v, ok
+}
+
+// GetString returns the string value associated with the given key and an existence check.
+// Returns an empty string/false if the value associated with the key does not exist or is not of type string.
+func (m Meta) GetString(key string) (string, bool) {
+	v, _ := m.Get(key)
+	return toString(v)
+}
+
+// GetInt returns the integer value associated with the given key and an existence check.
+// Returns 0/false if the value associated with the key does not exist, is not a number, or cannot be represented
+// as an int without loss.
+func (m Meta) GetInt(key string) (int, bool) {
+	v, _ := m.Get(key)
+	return toInt(v)
+}
+
+// GetInt64 returns the 64-bit integer value associated with the given key and an existence check.
+// Returns 0/false if the value associated with the key does not exist, is not a number, or cannot be represented
+// as an int64 without loss.
+func (m Meta) GetInt64(key string) (int64, bool) {
+	v, _ := m.Get(key)
+	return toInt64(v)
+}
+
+// GetUint64 returns the unsigned 64-bit integer value associated with the given key and an existence check.
+// Returns 0/false if the value associated with the key does not exist, is not a number, or cannot be represented
+// as a uint64 without loss.
+func (m Meta) GetUint64(key string) (uint64, bool) {
+	v, _ := m.Get(key)
+	return toUint64(v)
+}
+
+// GetBigInt returns the arbitrary-precision integer value associated with the given key and an existence check.
+// Returns nil/false if the value associated with the key does not exist or is not an integral number.
+// Integers beyond 2^53 can only be read without loss when decoded as json.Number, see Decoder.UseNumber.
+func (m Meta) GetBigInt(key string) (*big.Int, bool) {
+	v, _ := m.Get(key)
+	return toBigInt(v)
+}
+
+// GetBigRat returns the exact rational value associated with the given key and an existence check, e.g. for
+// decimal amounts. Returns nil/false if the value associated with the key does not exist or is not a number.
+// Decimals can only be read without loss when decoded as json.Number, see Decoder.UseNumber.
+func (m Meta) GetBigRat(key string) (*big.Rat, bool) {
+	v, _ := m.Get(key)
+	return toBigRat(v)
+}
+
+// GetFloat returns the floating point value associated with the given key and an existence check.
+// Returns 0/false if the value associated with the key does not exist or is not a number.
+func (m Meta) GetFloat(key string) (float64, bool) {
+	v, _ := m.Get(key)
+	return toFloat64(v)
+}
+
+// GetBool returns the boolean value associated with the given key and an existence check.
+// Returns false/false if the value associated with the key does not exist or is not of type bool.
+func (m Meta) GetBool(key string) (bool, bool) {
+	v, _ := m.Get(key)
+	return toBool(v)
+}
+
+// GetTime returns the time value associated with the given key and an existence check.
+// The value must either be a time.Time or a string in RFC 3339 format.
+// Returns the zero time/false if the value associated with the key does not exist or cannot be parsed.
+func (m Meta) GetTime(key string) (time.Time, bool) {
+	v, _ := m.Get(key)
+	return toTime(v)
+}
+
+// Delete deletes the value associated with the given key.
+func (m Meta) Delete(key string) {
+	if m == nil {
+		return
+	}
+	delete(m, key)
+}
+
+// Merge adds all key, value pairs of other to the meta object.
+// It overwrites any existing values associated with the same keys.
+func (m Meta) Merge(other Meta) {
+	if m == nil {
+		return
+	}
+	for k, v := range other {
+		m[k] = v
+	}
+}
@@ -0,0 +1,136 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smotes/jsonapi"
+)
+
+func TestMeta_Add_WhenNil(t *testing.T) {
+	var m jsonapi.Meta
+	defer catchPanic(t, "Meta", "Add")
+	m.Add("foo", "bar")
+}
+
+func TestMeta_Get_WhenNil(t *testing.T) {
+	var m jsonapi.Meta
+	defer catchPanic(t, "Meta", "Get")
+	if v, ok := m.Get("foo"); v != nil || ok {
+		t.Error("Meta.Get should return nil/false when Meta is nil")
+	}
+	m.GetString("foo")
+	m.GetInt("foo")
+	m.GetFloat("foo")
+	m.GetBool("foo")
+	m.GetTime("foo")
+}
+
+func TestMeta_Delete_WhenNil(t *testing.T) {
+	var m jsonapi.Meta
+	defer catchPanic(t, "Meta", "Delete")
+	m.Delete("foo")
+}
+
+func TestMeta_Merge_WhenNil(t *testing.T) {
+	var m jsonapi.Meta
+	defer catchPanic(t, "Meta", "Merge")
+	m.Merge(jsonapi.Meta{"foo": "bar"})
+}
+
+func TestMeta_Getters(t *testing.T) {
+	created := time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+	m := jsonapi.Meta{}
+	m.Add("string", "foo")
+	m.Add("int", 42)
+	m.Add("float", 4.2)
+	m.Add("integralFloat", float64(42))
+	m.Add("bool", true)
+	m.Add("time", created)
+	m.Add("timeString", created.Format(time.RFC3339))
+
+	if v, ok := m.GetString("string"); !ok || v != "foo" {
+		t.Errorf("unexpected value from Meta.GetString: %v", v)
+	}
+	if _, ok := m.GetString("int"); ok {
+		t.Error("Meta.GetString should return false for non-string values")
+	}
+	if v, ok := m.GetInt("int"); !ok || v != 42 {
+		t.Errorf("unexpected value from Meta.GetInt: %v", v)
+	}
+	if v, ok := m.GetInt("integralFloat"); !ok || v != 42 {
+		t.Errorf("unexpected value from Meta.GetInt for integral float64: %v", v)
+	}
+	if _, ok := m.GetInt("float"); ok {
+		t.Error("Meta.GetInt should return false for non-integral numbers")
+	}
+	if v, ok := m.GetFloat("float"); !ok || v != 4.2 {
+		t.Errorf("unexpected value from Meta.GetFloat: %v", v)
+	}
+	if v, ok := m.GetFloat("int"); !ok || v != 42 {
+		t.Errorf("unexpected value from Meta.GetFloat for int: %v", v)
+	}
+	if v, ok := m.GetBool("bool"); !ok || !v {
+		t.Errorf("unexpected value from Meta.GetBool: %v", v)
+	}
+	if v, ok := m.GetTime("time"); !ok || !v.Equal(created) {
+		t.Errorf("unexpected value from Meta.GetTime: %v", v)
+	}
+	if v, ok := m.GetTime("timeString"); !ok || !v.Equal(created) {
+		t.Errorf("unexpected value from Meta.GetTime for RFC 3339 string: %v", v)
+	}
+	if _, ok := m.GetTime("string"); ok {
+		t.Error("Meta.GetTime should return false for strings not in RFC 3339 format")
+	}
+}
+
+func TestMeta_Getters_FromJSON(t *testing.T) {
+	data := `{"total": 42, "ratio": 0.5, "big": 9007199254740993}`
+
+	m := jsonapi.Meta{}
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatalf("unexpected error when unmarshaling meta: %+v", err)
+	}
+	if v, ok := m.GetInt("total"); !ok || v != 42 {
+		t.Errorf("unexpected value from Meta.GetInt for float64: %v", v)
+	}
+	if _, ok := m.GetInt("big"); ok {
+		t.Error("Meta.GetInt should return false for float64 values beyond exact integer precision")
+	}
+
+	m = jsonapi.Meta{}
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		t.Fatalf("unexpected error when decoding meta: %+v", err)
+	}
+	if v, ok := m.GetInt("big"); !ok || v != 9007199254740993 {
+		t.Errorf("unexpected value from Meta.GetInt for json.Number: %v", v)
+	}
+	if v, ok := m.GetFloat("ratio"); !ok || v != 0.5 {
+		t.Errorf("unexpected value from Meta.GetFloat for json.Number: %v", v)
+	}
+}
+
+func TestMeta_Merge(t *testing.T) {
+	m := jsonapi.Meta{"foo": "bar", "baz": 1}
+	m.Merge(jsonapi.Meta{"baz": 2, "qux": 3})
+
+	if len(m) != 3 {
+		t.Errorf("unexpected number of keys after Meta.Merge: %d", len(m))
+	}
+	if v, _ := m.GetInt("baz"); v != 2 {
+		t.Errorf("Meta.Merge should overwrite existing keys, actual: %v", v)
+	}
+}
+
+func TestMeta_Delete(t *testing.T) {
+	m := jsonapi.Meta{}
+	m.Add("foo", "bar")
+	m.Delete("foo")
+	if _, ok := m.Get("foo"); ok {
+		t.Error("Meta.Get should return false after Meta.Delete for the given key")
+	}
+}
@@ -0,0 +1,158 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Nullable represents an attribute value which distinguishes between the three states an attribute may have
+// in a request: absent, explicitly null, or set to a value.
+//
+// This matters when updating resources, as the JSON API specification requires that only the attributes sent
+// in a PATCH request are applied, so an absent attribute must be left untouched while a null attribute must be
+// cleared. Nullable implements json.Unmarshaler, so it can be used as a field type with Resource.DecodeAttributes:
+//
+//	type articlePatch struct {
+//		Title jsonapi.Nullable[string] `json:"title"`
+//	}
+//
+// When encoding, an absent value is omitted from structs by the "omitzero" option of the "encoding/json" package,
+// and is otherwise encoded as null like an explicitly null value. Note that "omitzero" requires Go 1.24: with older
+// versions the option is ignored, so an absent value is encoded as null, which a server applies as clearing the
+// attribute. When building request documents with older versions, leave absent attributes out of the map passed
+// as attributes rather than relying on the struct tag.
+type Nullable[T any] struct {
+	Value   T
+	Valid   bool // Valid is true if the value is neither absent nor null.
+	Present bool // Present is true if the value was set, either to null or a value.
+}
+
+// NewNullable returns a Nullable set to the value v.
+func NewNullable[T any](v T) Nullable[T] {
+	return Nullable[T]{Value: v, Valid: true, Present: true}
+}
+
+// Null returns a Nullable explicitly set to null.
+func Null[T any]() Nullable[T] {
+	return Nullable[T]{Present: true}
+}
+
+// Get returns the value and true if the value is neither absent nor null.
+func (n Nullable[T]) Get() (T, bool) {
+	return n.Value, n.Valid
+}
+
+// IsNull returns true if the value was explicitly set to null.
+func (n Nullable[T]) IsNull() bool {
+	return n.Present && !n.Valid
+}
+
+// IsZero returns true if the value is absent, allowing it to be omitted with the "omitzero" struct tag option
+// (Go 1.24 or later, see Nullable).
+func (n Nullable[T]) IsZero() bool {
+	return !n.Present
+}
+
+// MarshalJSON encodes the value, or null if it is absent or null.
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Value)
+}
+
+// UnmarshalJSON decodes null or a value of type T, marking the Nullable as present either way.
+func (n *Nullable[T]) UnmarshalJSON(b []byte) error {
+	if isJSONNull(b) {
+		*n = Null[T]()
+		return nil
+	}
+
+	var v T
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*n = NewNullable(v)
+	return nil
+}
+
+// Optional represents an attribute value which may be absent or set to a value, but must not be null.
+//
+// Like Nullable, it distinguishes absent attributes from attributes sent with a zero value, for attributes
+// which cannot be cleared. The same caveat about encoding absent values with Go versions before 1.24 applies.
+type Optional[T any] struct {
+	Value   T
+	Present bool // Present is true if the value was set.
+}
+
+// NewOptional returns an Optional set to the value v.
+func NewOptional[T any](v T) Optional[T] {
+	return Optional[T]{Value: v, Present: true}
+}
+
+// Get returns the value and true if the value is present.
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.Present
+}
+
+// IsZero returns true if the value is absent, allowing it to be omitted with the "omitzero" struct tag option
+// (Go 1.24 or later, see Nullable).
+func (o Optional[T]) IsZero() bool {
+	return !o.Present
+}
+
+// MarshalJSON encodes the value, or null if it is absent.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Present {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// UnmarshalJSON decodes a value of type T, marking the Optional as present.
+// It returns an error for null values.
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	if isJSONNull(b) {
+		return errOptionalNull
+	}
+
+	var v T
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*o = NewOptional(v)
+	return nil
+}
+
+// NullableAttribute returns the attribute key of as decoded into a Nullable, for use in SetAttributes
+// implementations. The returned Nullable is absent if the attribute does not exist.
+//
+// If the attribute cannot be decoded into a value of type T, NullableAttribute returns a 400 Bad Request *Error
+// with a source pointer such as "/data/attributes/title".
+func NullableAttribute[T any](as Attributes, key string) (Nullable[T], error) {
+	var n Nullable[T]
+
+	v, ok := as.Get(key)
+	if !ok {
+		return n, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return n, err
+	}
+	if err := n.UnmarshalJSON(b); err != nil {
+		e := attributeError(key, err)
+		return n, &e
+	}
+	return n, nil
+}
+
+func isJSONNull(b []byte) bool {
+	return bytes.Equal(bytes.TrimSpace(b), []byte("null"))
+}
+
+// errors
+
+var errOptionalNull = fmt.Errorf("%s: value must not be null", packageName)
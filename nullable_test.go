@@ -0,0 +1,99 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+type articlePatch struct {
+	Title    jsonapi.Nullable[string] `json:"title,omitzero"`
+	Body     jsonapi.Nullable[string] `json:"body,omitzero"`
+	Subtitle jsonapi.Nullable[string] `json:"subtitle,omitzero"`
+	Views    jsonapi.Optional[int]    `json:"views,omitzero"`
+}
+
+func TestNullable_DecodeAttributes(t *testing.T) {
+	r := jsonapi.Resource{}
+	if err := json.Unmarshal([]byte(`
+	{
+		"id": "1",
+		"type": "articles",
+		"attributes": {"title": "foo", "body": null}
+	}`), &r); err != nil {
+		t.Fatalf("unexpected error when unmarshaling resource: %+v", err)
+	}
+
+	var patch articlePatch
+	if err := r.DecodeAttributes(&patch); err != nil {
+		t.Fatalf("unexpected error from Resource.DecodeAttributes: %+v", err)
+	}
+
+	if v, ok := patch.Title.Get(); !ok || v != "foo" || !patch.Title.Present {
+		t.Errorf("expected Nullable to hold value, actual: %+v", patch.Title)
+	}
+	if !patch.Body.IsNull() {
+		t.Errorf("expected Nullable to be null, actual: %+v", patch.Body)
+	}
+	if patch.Subtitle.Present || patch.Subtitle.IsNull() {
+		t.Errorf("expected Nullable to be absent, actual: %+v", patch.Subtitle)
+	}
+	if patch.Views.Present {
+		t.Errorf("expected Optional to be absent, actual: %+v", patch.Views)
+	}
+
+	if !r.HasAttribute("body") || r.HasAttribute("subtitle") {
+		t.Error("Resource.HasAttribute should return true only for attributes present in the resource")
+	}
+	if !r.Attributes.IsNull("body") || r.Attributes.IsNull("title") {
+		t.Error("Attributes.IsNull should return true only for attributes with null values")
+	}
+}
+
+func TestOptional_DecodeAttributes_WhenNull(t *testing.T) {
+	r := jsonapi.Resource{Attributes: jsonapi.Attributes{"views": nil}}
+
+	var patch articlePatch
+	var errs jsonapi.Errors
+	if err := r.DecodeAttributes(&patch); !errors.As(err, &errs) || errs[0].Source.Pointer != "/data/attributes/views" {
+		t.Errorf("expected error with source pointer for null Optional, actual: %+v", err)
+	}
+}
+
+func TestNullable_MarshalJSON(t *testing.T) {
+	patch := articlePatch{
+		Title: jsonapi.NewNullable("foo"),
+		Body:  jsonapi.Null[string](),
+		Views: jsonapi.NewOptional(42),
+	}
+
+	b, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatalf("unexpected error when marshaling: %+v", err)
+	}
+	if err := compareJSON(`{"title": "foo", "body": null, "views": 42}`, string(b)); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
+
+func TestNullableAttribute(t *testing.T) {
+	as := jsonapi.Attributes{"title": "foo", "body": nil, "views": "many"}
+
+	if n, err := jsonapi.NullableAttribute[string](as, "title"); err != nil || n.Value != "foo" || !n.Valid {
+		t.Errorf("unexpected result from NullableAttribute: %+v, %v", n, err)
+	}
+	if n, err := jsonapi.NullableAttribute[string](as, "body"); err != nil || !n.IsNull() {
+		t.Errorf("unexpected result from NullableAttribute for null value: %+v, %v", n, err)
+	}
+	if n, err := jsonapi.NullableAttribute[string](as, "missing"); err != nil || n.Present {
+		t.Errorf("unexpected result from NullableAttribute for missing value: %+v, %v", n, err)
+	}
+
+	_, err := jsonapi.NullableAttribute[int](as, "views")
+	var e *jsonapi.Error
+	if !errors.As(err, &e) || e.Source.Pointer != "/data/attributes/views" {
+		t.Errorf("expected error with source pointer from NullableAttribute, actual: %+v", err)
+	}
+}
@@ -0,0 +1,536 @@
+// Package openapi generates OpenAPI 3.1 documents describing the JSON API endpoints of the resource types
+// registered with a jsonapi.Registry, so the documentation of an API is derived from the same schemas as the code
+// serving it.
+//
+// For each registered type, the generated document describes the collection, resource, related resource and
+// relationship endpoints, as served by the server package, along with the query parameters, the request and
+// response documents and the error responses of each endpoint:
+//
+//	reg := &jsonapi.Registry{}
+//	reg.Register(jsonapi.ResourceSchema{Type: "articles", ...})
+//	err := openapi.Write(os.Stdout, reg, openapi.Info{Title: "Blog", Version: "1.0.0"})
+//
+// The output only depends on the registered schemas, so it is stable across runs and may be checked in.
+package openapi // import "github.com/smotes/jsonapi/openapi"
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/smotes/jsonapi"
+	"github.com/smotes/jsonapi/jsonschema"
+)
+
+// Version is the version of the OpenAPI specification of the generated documents.
+const Version = "3.1.0"
+
+// Info holds the metadata of the generated document.
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+
+	// Servers are the URLs the API is served at, e.g. "https://example.com/api".
+	Servers []string
+}
+
+// Generate returns the OpenAPI document describing the resource types registered with reg, as a tree of maps and
+// slices ready to be encoded as JSON.
+func Generate(reg *jsonapi.Registry, info Info) map[string]interface{} {
+	g := &generator{
+		paths:   map[string]interface{}{},
+		schemas: commonSchemas(),
+	}
+	for _, typ := range reg.Types() {
+		s, _ := reg.Schema(typ)
+		g.resourceType(s)
+	}
+
+	doc := map[string]interface{}{
+		"openapi": Version,
+		"info":    g.info(info),
+		"paths":   g.paths,
+		"components": map[string]interface{}{
+			"schemas":    g.schemas,
+			"parameters": parameters(),
+			"responses":  errorResponses(),
+		},
+	}
+	if len(info.Servers) > 0 {
+		servers := make([]interface{}, len(info.Servers))
+		for i, url := range info.Servers {
+			servers[i] = map[string]interface{}{"url": url}
+		}
+		doc["servers"] = servers
+	}
+	return doc
+}
+
+// Write writes the OpenAPI document describing the resource types registered with reg to w, as indented JSON.
+func Write(w io.Writer, reg *jsonapi.Registry, info Info) error {
+	b, err := json.MarshalIndent(Generate(reg, info), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+type generator struct {
+	paths   map[string]interface{}
+	schemas map[string]interface{}
+}
+
+func (g *generator) info(info Info) map[string]interface{} {
+	m := map[string]interface{}{
+		"title":   info.Title,
+		"version": info.Version,
+	}
+	if len(info.Description) > 0 {
+		m["description"] = info.Description
+	}
+	return m
+}
+
+// resourceType adds the schemas and endpoints of the resource type described by s.
+func (g *generator) resourceType(s *jsonapi.ResourceSchema) {
+	name := typeComponent(s.Type)
+
+	g.schemas[name] = resourceSchema(s, resourceRead)
+	g.schemas[name+".create"] = resourceSchema(s, resourceCreate)
+	g.schemas[name+".update"] = resourceSchema(s, resourceUpdate)
+	g.schemas[name+".document"] = documentSchema(ref(name), true)
+	g.schemas[name+".collectionDocument"] = documentSchema(arrayOf(ref(name)), true)
+	g.schemas[name+".createDocument"] = documentSchema(ref(name+".create"), false)
+	g.schemas[name+".updateDocument"] = documentSchema(ref(name+".update"), false)
+
+	tags := []string{s.Type}
+	collection := "/" + s.Type
+	resource := collection + "/{id}"
+
+	g.paths[collection] = map[string]interface{}{
+		"get": operation(s.Type+".list", "List "+s.Type, tags, queryParameters(true),
+			nil, http.StatusOK, name+".collectionDocument", http.StatusBadRequest),
+		"post": operation(s.Type+".create", "Create a resource of type "+s.Type, tags, queryParameters(false),
+			ref(name+".createDocument"), http.StatusCreated, name+".document",
+			http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+			http.StatusUnsupportedMediaType),
+	}
+	g.paths[resource] = map[string]interface{}{
+		"parameters": []interface{}{ref("#/components/parameters/id")},
+		"get": operation(s.Type+".get", "Fetch a resource of type "+s.Type, tags, queryParameters(false),
+			nil, http.StatusOK, name+".document", http.StatusBadRequest, http.StatusNotFound),
+		"patch": operation(s.Type+".update", "Update a resource of type "+s.Type, tags, queryParameters(false),
+			ref(name+".updateDocument"), http.StatusOK, name+".document",
+			http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+			http.StatusUnsupportedMediaType),
+		"delete": operation(s.Type+".delete", "Delete a resource of type "+s.Type, tags, nil,
+			nil, http.StatusNoContent, "", http.StatusNotFound),
+	}
+
+	for _, rel := range s.Relationships {
+		g.relationship(s, rel)
+	}
+}
+
+// relationship adds the schemas and the related resource and relationship endpoints of the relationship rel.
+func (g *generator) relationship(s *jsonapi.ResourceSchema, rel jsonapi.RelationshipSchema) {
+	name := relationshipComponent(s.Type, rel.Name)
+	tags := []string{s.Type}
+	id := []interface{}{ref("#/components/parameters/id")}
+
+	// the primary data of the related resource endpoint may be of any of the target types
+	var related interface{}
+	if len(rel.Types) == 1 {
+		related = ref(typeComponent(rel.Types[0]))
+	} else {
+		targets := make([]interface{}, len(rel.Types))
+		for i, typ := range rel.Types {
+			targets[i] = ref(typeComponent(typ))
+		}
+		related = map[string]interface{}{"oneOf": targets}
+	}
+	if rel.ToMany {
+		related = arrayOf(related)
+	} else {
+		related = nullable(related)
+	}
+	g.schemas[name+".relatedDocument"] = documentSchema(related, true)
+	g.schemas[name+".linkage"] = jsonschema.Linkage(rel)
+	g.schemas[name+".document"] = documentSchema(ref(name+".linkage"), true)
+	g.schemas[name+".updateDocument"] = documentSchema(ref(name+".linkage"), false)
+
+	g.paths["/"+s.Type+"/{id}/"+rel.Name] = map[string]interface{}{
+		"parameters": id,
+		"get": operation(s.Type+"."+rel.Name+".related", "Fetch the related "+rel.Name, tags,
+			queryParameters(rel.ToMany), nil, http.StatusOK, name+".relatedDocument",
+			http.StatusBadRequest, http.StatusNotFound),
+	}
+
+	endpoint := map[string]interface{}{
+		"parameters": id,
+		"get": operation(s.Type+"."+rel.Name+".relationship", "Fetch the "+rel.Name+" relationship", tags,
+			nil, nil, http.StatusOK, name+".document", http.StatusNotFound),
+	}
+	update := func(method, verb string) {
+		endpoint[method] = operation(s.Type+"."+rel.Name+"."+method, verb+" the "+rel.Name+" relationship",
+			tags, nil, ref(name+".updateDocument"), http.StatusNoContent, "",
+			http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict,
+			http.StatusUnsupportedMediaType)
+	}
+	update("patch", "Replace")
+	if rel.ToMany {
+		update("post", "Add to")
+		update("delete", "Remove from")
+	}
+	g.paths["/"+s.Type+"/{id}/relationships/"+rel.Name] = endpoint
+}
+
+// resourceMode selects the variant of a resource object schema.
+type resourceMode int
+
+const (
+	resourceRead   resourceMode = iota // resource objects returned to clients
+	resourceCreate                     // resource objects sent to create a resource
+	resourceUpdate                     // resource objects sent to update a resource
+)
+
+// resourceSchema returns the schema of the resource objects of the type described by s, leaving out the
+// attributes clients must not see or send, depending on mode.
+func resourceSchema(s *jsonapi.ResourceSchema, mode resourceMode) map[string]interface{} {
+	var payload *jsonschema.Payload
+	switch mode {
+	case resourceCreate:
+		p := jsonschema.CreatePayload
+		payload = &p
+	case resourceUpdate:
+		p := jsonschema.UpdatePayload
+		payload = &p
+	}
+	attributes := map[string]interface{}{}
+	for _, a := range s.Attributes {
+		if jsonschema.Allowed(a, payload) {
+			attributes[a.Name] = map[string]interface{}(jsonschema.Attribute(a))
+		}
+	}
+
+	relationships := map[string]interface{}{}
+	for _, rel := range s.Relationships {
+		prop := map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"data": ref(relationshipComponent(s.Type, rel.Name) + ".linkage"),
+			},
+		}
+		if mode == resourceRead {
+			prop["properties"].(map[string]interface{})["links"] = ref("links")
+			prop["properties"].(map[string]interface{})["meta"] = ref("meta")
+		} else {
+			prop["required"] = []string{"data"}
+		}
+		relationships[rel.Name] = prop
+	}
+
+	properties := map[string]interface{}{
+		"type": map[string]interface{}{"type": "string", "enum": []string{s.Type}},
+		"id":   map[string]interface{}{"type": "string"},
+	}
+	required := []string{"type", "id"}
+	switch mode {
+	case resourceRead:
+		properties["links"] = ref("links")
+		properties["meta"] = ref("meta")
+	case resourceCreate:
+		properties["lid"] = map[string]interface{}{"type": "string"}
+		required = []string{"type"}
+	}
+	if len(attributes) > 0 {
+		properties["attributes"] = map[string]interface{}{
+			"type":                 "object",
+			"properties":           attributes,
+			"additionalProperties": false,
+		}
+	}
+	if len(relationships) > 0 {
+		properties["relationships"] = map[string]interface{}{
+			"type":                 "object",
+			"properties":           relationships,
+			"additionalProperties": false,
+		}
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"required":   required,
+		"properties": properties,
+	}
+}
+
+// documentSchema returns the schema of a document with the given primary data, along with the members of response
+// documents if response is true.
+func documentSchema(data interface{}, response bool) map[string]interface{} {
+	properties := map[string]interface{}{
+		"data": data,
+		"meta": ref("meta"),
+	}
+	if response {
+		properties["included"] = arrayOf(map[string]interface{}{"$ref": "#/components/schemas/resource"})
+		properties["links"] = ref("links")
+		properties["jsonapi"] = ref("jsonapi")
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"required":   []string{"data"},
+		"properties": properties,
+	}
+}
+
+// commonSchemas returns the schemas shared by all resource types.
+func commonSchemas() map[string]interface{} {
+	return map[string]interface{}{
+		"meta": map[string]interface{}{"type": "object"},
+		"links": map[string]interface{}{
+			"type": "object",
+			"additionalProperties": map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					ref("link"),
+					map[string]interface{}{"type": "null"},
+				},
+			},
+		},
+		"link": map[string]interface{}{
+			"type":     "object",
+			"required": []string{"href"},
+			"properties": map[string]interface{}{
+				"href":  map[string]interface{}{"type": "string"},
+				"rel":   map[string]interface{}{"type": "string"},
+				"title": map[string]interface{}{"type": "string"},
+				"type":  map[string]interface{}{"type": "string"},
+				"describedby": map[string]interface{}{
+					"oneOf": []interface{}{map[string]interface{}{"type": "string"}, ref("link")},
+				},
+				"hreflang": map[string]interface{}{
+					"oneOf": []interface{}{
+						map[string]interface{}{"type": "string"},
+						arrayOf(map[string]interface{}{"type": "string"}),
+					},
+				},
+				"meta": ref("meta"),
+			},
+		},
+		"jsonapi": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"version": map[string]interface{}{"type": "string"},
+				"ext":     arrayOf(map[string]interface{}{"type": "string"}),
+				"profile": arrayOf(map[string]interface{}{"type": "string"}),
+				"meta":    ref("meta"),
+			},
+		},
+		"resource": map[string]interface{}{
+			"type":     "object",
+			"required": []string{"type", "id"},
+			"properties": map[string]interface{}{
+				"type":          map[string]interface{}{"type": "string"},
+				"id":            map[string]interface{}{"type": "string"},
+				"attributes":    map[string]interface{}{"type": "object"},
+				"relationships": map[string]interface{}{"type": "object"},
+				"links":         ref("links"),
+				"meta":          ref("meta"),
+			},
+		},
+		"error": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id":     map[string]interface{}{"type": "string"},
+				"status": map[string]interface{}{"type": "string"},
+				"code":   map[string]interface{}{"type": "string"},
+				"title":  map[string]interface{}{"type": "string"},
+				"detail": map[string]interface{}{"type": "string"},
+				"source": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"pointer":   map[string]interface{}{"type": "string"},
+						"parameter": map[string]interface{}{"type": "string"},
+						"header":    map[string]interface{}{"type": "string"},
+					},
+				},
+				"links": ref("links"),
+				"meta":  ref("meta"),
+			},
+		},
+		"errorDocument": map[string]interface{}{
+			"type":     "object",
+			"required": []string{"errors"},
+			"properties": map[string]interface{}{
+				"errors":  arrayOf(ref("error")),
+				"meta":    ref("meta"),
+				"links":   ref("links"),
+				"jsonapi": ref("jsonapi"),
+			},
+		},
+	}
+}
+
+// parameters returns the parameters shared by all endpoints.
+func parameters() map[string]interface{} {
+	str := map[string]interface{}{"type": "string"}
+	deepObject := func(name, description string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":        name,
+			"in":          "query",
+			"description": description,
+			"style":       "deepObject",
+			"explode":     true,
+			"schema": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": str,
+			},
+		}
+	}
+	return map[string]interface{}{
+		"id": map[string]interface{}{
+			"name":     "id",
+			"in":       "path",
+			"required": true,
+			"schema":   str,
+		},
+		"include": map[string]interface{}{
+			"name":        "include",
+			"in":          "query",
+			"description": "Comma-separated relationship paths of the resources to include, e.g. comments.author.",
+			"schema":      str,
+		},
+		"fields": deepObject("fields",
+			"Comma-separated fields to return for each resource type, e.g. fields[articles]=title."),
+		"sort": map[string]interface{}{
+			"name":        "sort",
+			"in":          "query",
+			"description": "Comma-separated fields to sort by, in descending order if prefixed with a minus.",
+			"schema":      str,
+		},
+		"page":   deepObject("page", "Pagination of the collection, e.g. page[number] and page[size]."),
+		"filter": deepObject("filter", "Filters of the collection, e.g. filter[title]=foo."),
+	}
+}
+
+// queryParameters returns the references to the query parameters of an endpoint, along with those only
+// applying to collections if collection is true.
+func queryParameters(collection bool) []interface{} {
+	names := []string{"include", "fields"}
+	if collection {
+		names = append(names, "sort", "page", "filter")
+	}
+	params := make([]interface{}, len(names))
+	for i, name := range names {
+		params[i] = ref("#/components/parameters/" + name)
+	}
+	return params
+}
+
+// errorStatuses are the statuses of the error responses described by the generated documents.
+var errorStatuses = []int{
+	http.StatusBadRequest,
+	http.StatusForbidden,
+	http.StatusNotFound,
+	http.StatusNotAcceptable,
+	http.StatusConflict,
+	http.StatusUnsupportedMediaType,
+}
+
+// errorResponses returns the error responses, named by their status.
+func errorResponses() map[string]interface{} {
+	responses := map[string]interface{}{}
+	for _, status := range errorStatuses {
+		responses[statusName(status)] = map[string]interface{}{
+			"description": http.StatusText(status),
+			"content":     content(ref("errorDocument")),
+		}
+	}
+	return responses
+}
+
+// operation returns an operation with the given request document schema, response status and document schema
+// name, and error response statuses, along with 406 Not Acceptable, which applies to all operations.
+func operation(id, summary string, tags []string, params []interface{}, request interface{}, status int,
+	response string, statuses ...int) map[string]interface{} {
+	responses := map[string]interface{}{}
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	if len(response) > 0 {
+		success["content"] = content(ref(response))
+	}
+	responses[strconv.Itoa(status)] = success
+	for _, e := range append(statuses, http.StatusNotAcceptable) {
+		responses[strconv.Itoa(e)] = ref("#/components/responses/" + statusName(e))
+	}
+
+	op := map[string]interface{}{
+		"operationId": id,
+		"summary":     summary,
+		"tags":        tags,
+		"responses":   responses,
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	if request != nil {
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  content(request),
+		}
+	}
+	return op
+}
+
+// helpers
+
+// invalidComponentChars matches the characters not allowed in the names of components, along with dots, which
+// separate the parts of the names of the components generated for each resource type.
+var invalidComponentChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// componentName returns name with any characters not allowed in a part of the name of a component replaced.
+func componentName(name string) string {
+	return invalidComponentChars.ReplaceAllString(name, "_")
+}
+
+// typeComponent returns the name of the resource schema of the resource type typ, e.g. "types.articles", which is
+// also the prefix of the names of its other components, e.g. "types.articles.document". The prefix keeps them apart
+// from the common components, such as "error" or "resource", whatever the name of the type.
+func typeComponent(typ string) string {
+	return "types." + componentName(typ)
+}
+
+// relationshipComponent returns the prefix of the names of the components of the relationship name of the resource
+// type typ, e.g. "types.articles.relationships.author".
+func relationshipComponent(typ, name string) string {
+	return typeComponent(typ) + ".relationships." + componentName(name)
+}
+
+// ref returns a reference to the schema component name, or to name itself if it is a reference already.
+func ref(name string) map[string]interface{} {
+	if len(name) > 0 && name[0] == '#' {
+		return map[string]interface{}{"$ref": name}
+	}
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func arrayOf(items interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "array", "items": items}
+}
+
+func nullable(schema interface{}) map[string]interface{} {
+	return map[string]interface{}{"oneOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+}
+
+func content(schema interface{}) map[string]interface{} {
+	return map[string]interface{}{jsonapi.MediaType: map[string]interface{}{"schema": schema}}
+}
+
+func statusName(status int) string {
+	return strings.Replace(http.StatusText(status), " ", "", -1)
+}
@@ -0,0 +1,222 @@
+package openapi_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+	"github.com/smotes/jsonapi/openapi"
+)
+
+func newTestRegistry(t *testing.T) *jsonapi.Registry {
+	reg := &jsonapi.Registry{}
+	schemas := []jsonapi.ResourceSchema{
+		{
+			Type: "articles",
+			Attributes: []jsonapi.AttributeSchema{
+				{Name: "title", Type: jsonapi.StringType},
+				{Name: "summary", Type: jsonapi.StringType, Nullable: true},
+				{Name: "views", Type: jsonapi.IntegerType, Access: jsonapi.ReadOnly},
+				{Name: "slug", Type: jsonapi.StringType, Access: jsonapi.CreateOnly},
+			},
+			Relationships: []jsonapi.RelationshipSchema{
+				{Name: "author", Types: []string{"people"}},
+				{Name: "comments", ToMany: true, Types: []string{"comments"}},
+			},
+		},
+		{
+			Type: "people",
+			Attributes: []jsonapi.AttributeSchema{
+				{Name: "name", Type: jsonapi.StringType},
+				{Name: "password", Type: jsonapi.StringType, Access: jsonapi.WriteOnly},
+			},
+		},
+		{
+			Type: "comments",
+			Relationships: []jsonapi.RelationshipSchema{
+				{Name: "subject", Types: []string{"articles", "people"}},
+			},
+		},
+	}
+	for _, s := range schemas {
+		if err := reg.Register(s); err != nil {
+			t.Fatalf("unexpected error from Register: %+v", err)
+		}
+	}
+	return reg
+}
+
+func generate(t *testing.T) map[string]interface{} {
+	buf := &bytes.Buffer{}
+	if err := openapi.Write(buf, newTestRegistry(t), openapi.Info{Title: "Blog", Version: "1.0.0"}); err != nil {
+		t.Fatalf("unexpected error from Write: %+v", err)
+	}
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("unexpected error decoding document: %+v", err)
+	}
+	return doc
+}
+
+// lookup returns the value at the path of keys within the decoded document v.
+func lookup(v interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+func TestWrite_Stable(t *testing.T) {
+	var outputs []string
+	for i := 0; i < 5; i++ {
+		buf := &bytes.Buffer{}
+		if err := openapi.Write(buf, newTestRegistry(t), openapi.Info{Title: "Blog", Version: "1.0.0"}); err != nil {
+			t.Fatalf("unexpected error from Write: %+v", err)
+		}
+		outputs = append(outputs, buf.String())
+	}
+	for _, out := range outputs[1:] {
+		if out != outputs[0] {
+			t.Fatal("expected output to be the same across runs")
+		}
+	}
+}
+
+func TestGenerate_Paths(t *testing.T) {
+	doc := generate(t)
+
+	if v := lookup(doc, "openapi"); v != openapi.Version {
+		t.Errorf("unexpected version: %v", v)
+	}
+
+	tests := []struct {
+		path    string
+		methods []string
+	}{
+		{"/articles", []string{"get", "post"}},
+		{"/articles/{id}", []string{"get", "patch", "delete"}},
+		{"/articles/{id}/author", []string{"get"}},
+		{"/articles/{id}/relationships/author", []string{"get", "patch"}},
+		{"/articles/{id}/relationships/comments", []string{"get", "patch", "post", "delete"}},
+		{"/comments/{id}/relationships/subject", []string{"get", "patch"}},
+		{"/people", []string{"get", "post"}},
+	}
+	for _, test := range tests {
+		item, ok := lookup(doc, "paths", test.path).(map[string]interface{})
+		if !ok {
+			t.Errorf("%s: expected path to be described", test.path)
+			continue
+		}
+		for _, method := range test.methods {
+			if lookup(item, method, "responses", "406") == nil {
+				t.Errorf("%s: expected %s operation with error responses", test.path, method)
+			}
+		}
+	}
+	if lookup(doc, "paths", "/articles/{id}/relationships/author", "post") != nil {
+		t.Error("expected no POST operation for to-one relationship")
+	}
+	if lookup(doc, "paths", "/people/{id}/relationships/articles") != nil {
+		t.Error("expected no endpoints for undeclared relationship")
+	}
+
+	params := lookup(doc, "paths", "/articles", "get", "parameters").([]interface{})
+	var names []string
+	for _, p := range params {
+		ref := lookup(p, "$ref").(string)
+		names = append(names, ref[strings.LastIndex(ref, "/")+1:])
+	}
+	if strings.Join(names, ",") != "include,fields,sort,page,filter" {
+		t.Errorf("unexpected query parameters of collection: %v", names)
+	}
+}
+
+func TestGenerate_Schemas(t *testing.T) {
+	doc := generate(t)
+	schemas := lookup(doc, "components", "schemas")
+
+	attrs := func(name string) map[string]interface{} {
+		m, _ := lookup(schemas, name, "properties", "attributes", "properties").(map[string]interface{})
+		return m
+	}
+	if a := attrs("types.articles"); a["views"] == nil || a["slug"] == nil || lookup(a, "views", "readOnly") != true {
+		t.Errorf("unexpected attributes of resource schema: %v", a)
+	}
+	if a := attrs("types.articles.create"); a["views"] != nil || a["slug"] == nil {
+		t.Errorf("unexpected attributes of create schema: %v", a)
+	}
+	if a := attrs("types.articles.update"); a["views"] != nil || a["slug"] != nil {
+		t.Errorf("unexpected attributes of update schema: %v", a)
+	}
+	if a := attrs("types.people"); a["password"] != nil {
+		t.Errorf("expected write-only attribute not to be part of resource schema: %v", a)
+	}
+	if typ := lookup(attrs("types.articles"), "summary", "type"); len(typ.([]interface{})) != 2 {
+		t.Errorf("expected nullable attribute to allow null: %v", typ)
+	}
+
+	linkage := lookup(schemas, "types.comments.relationships.subject.linkage", "oneOf").([]interface{})
+	if types := lookup(linkage[0], "properties", "type", "enum").([]interface{}); len(types) != 2 {
+		t.Errorf("unexpected types of polymorphic relationship: %v", types)
+	}
+	if required := lookup(linkage[0], "required").([]interface{}); len(required) != 1 || lookup(linkage[0], "anyOf") == nil {
+		t.Errorf("expected resource identifiers to hold either an id or a lid: %v", linkage[0])
+	}
+
+	if lookup(schemas, "link", "properties", "describedby") == nil ||
+		len(lookup(schemas, "link", "properties", "hreflang", "oneOf").([]interface{})) != 2 {
+		t.Errorf("expected link schema to describe all link members: %v", lookup(schemas, "link"))
+	}
+
+	// all references must resolve
+	refs := map[string]bool{}
+	collectRefs(doc, refs)
+	for ref := range refs {
+		keys := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+		if lookup(doc, keys...) == nil {
+			t.Errorf("unresolved reference: %s", ref)
+		}
+	}
+}
+
+func collectRefs(v interface{}, refs map[string]bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if s, ok := child.(string); ok && key == "$ref" {
+				refs[s] = true
+			}
+			collectRefs(child, refs)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectRefs(child, refs)
+		}
+	}
+}
+
+func TestGenerate_ComponentNames(t *testing.T) {
+	reg := &jsonapi.Registry{}
+	for _, typ := range []string{"error", "link", "resource", "a.b"} {
+		if err := reg.Register(jsonapi.ResourceSchema{Type: typ}); err != nil {
+			t.Fatalf("unexpected error from Register: %+v", err)
+		}
+	}
+	schemas := lookup(openapi.Generate(reg, openapi.Info{}), "components", "schemas")
+
+	// the components of a type must not replace the common ones, whatever its name
+	if lookup(schemas, "error", "properties", "status") == nil || lookup(schemas, "link", "required") == nil {
+		t.Error("expected common schemas not to be replaced by those of resource types")
+	}
+	for _, name := range []string{"types.error", "types.link", "types.resource", "types.a_b.document"} {
+		if lookup(schemas, name) == nil {
+			t.Errorf("expected schema %q to be generated", name)
+		}
+	}
+}
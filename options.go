@@ -0,0 +1,131 @@
+package jsonapi
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// FromResourceOption configures the checks FromResource performs on a resource sent by a client,
+// before any of the adapter's setters are called.
+type FromResourceOption func(*fromResourceOptions)
+
+type fromResourceOptions struct {
+	intent       intent
+	expectedType string
+	expectedID   string
+
+	clientIDPolicy    ClientIDPolicy
+	clientIDValidator func(string) error
+	clientIDLookup    func(string, string) (bool, error)
+
+	localIDs *LocalIDs
+}
+
+type intent int
+
+const (
+	intentUnknown intent = iota
+	intentCreate
+	intentUpdate
+)
+
+func (i intent) String() string {
+	switch i {
+	case intentCreate:
+		return "creating"
+	case intentUpdate:
+		return "updating"
+	default:
+		return "setting"
+	}
+}
+
+// ForCreate declares that the resource was sent by a client to create a new resource, e.g. in a POST request.
+//
+// Attributes which are declared as read-only by the adapter are rejected with a 403 Forbidden error.
+func ForCreate() FromResourceOption {
+	return func(o *fromResourceOptions) {
+		o.intent = intentCreate
+	}
+}
+
+// ForUpdate declares that the resource was sent by a client to update an existing resource, e.g. in a PATCH request.
+//
+// Attributes which are declared as read-only or create-only by the adapter are rejected with a 403 Forbidden error.
+func ForUpdate() FromResourceOption {
+	return func(o *fromResourceOptions) {
+		o.intent = intentUpdate
+	}
+}
+
+// ExpectType declares the resource type of the endpoint the resource was sent to, e.g. "articles" for "/articles".
+//
+// A resource with a missing type is rejected with a 400 Bad Request error, and a resource of any other type
+// with a 409 Conflict error, as required by the JSON API specification.
+func ExpectType(typ string) FromResourceOption {
+	return func(o *fromResourceOptions) {
+		o.expectedType = typ
+	}
+}
+
+// ExpectID declares the ID of the resource in the URL of the endpoint the resource was sent to,
+// e.g. "1" for "/articles/1".
+//
+// A resource with a missing ID is rejected with a 400 Bad Request error, and a resource with any other ID
+// with a 409 Conflict error, as required by the JSON API specification.
+func ExpectID(id string) FromResourceOption {
+	return func(o *fromResourceOptions) {
+		o.expectedID = id
+	}
+}
+
+// checkIdentity checks the type and ID of r against the expected ones, if any.
+func (o *fromResourceOptions) checkIdentity(r *Resource) error {
+	var errs Errors
+	if len(o.expectedType) > 0 {
+		if e := identityError("type", r.Type, o.expectedType); e != nil {
+			errs = append(errs, *e)
+		}
+	}
+	if len(o.expectedID) > 0 {
+		if e := identityError("id", r.ID, o.expectedID); e != nil {
+			errs = append(errs, *e)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func identityError(member, actual, expected string) *Error {
+	switch actual {
+	case expected:
+		return nil
+
+	case "":
+		return &Error{
+			Status: fmt.Sprint(http.StatusBadRequest),
+			Title:  "Missing " + member,
+			Detail: fmt.Sprintf("resource must have the %s %q", member, expected),
+			Source: &ErrorSource{Pointer: "/data"},
+		}
+
+	default:
+		return &Error{
+			Status: fmt.Sprint(http.StatusConflict),
+			Title:  "Conflicting " + member,
+			Detail: fmt.Sprintf("resource %s %q does not match the endpoint's %s %q", member, actual, member, expected),
+			Source: &ErrorSource{Pointer: jsonPointer("data", member)},
+		}
+	}
+}
+
+func newFromResourceOptions(opts []FromResourceOption) *fromResourceOptions {
+	o := &fromResourceOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -0,0 +1,221 @@
+package jsonapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TargetKind represents the kind of endpoint a request path is routed to, see PathParser.
+type TargetKind int
+
+const (
+	// CollectionTarget is a resource collection, e.g. "/articles".
+	CollectionTarget TargetKind = iota
+
+	// ResourceTarget is an individual resource, e.g. "/articles/1".
+	ResourceTarget
+
+	// RelatedTarget is the related resource or collection of a relationship, e.g. "/articles/1/author".
+	RelatedTarget
+
+	// RelationshipTarget is the relationship itself, e.g. "/articles/1/relationships/author".
+	RelationshipTarget
+)
+
+func (k TargetKind) String() string {
+	switch k {
+	case CollectionTarget:
+		return "collection"
+	case ResourceTarget:
+		return "resource"
+	case RelatedTarget:
+		return "related"
+	case RelationshipTarget:
+		return "relationship"
+	}
+	return fmt.Sprintf("TargetKind(%d)", int(k))
+}
+
+// Target represents the endpoint a request path is routed to, i.e. its kind along with the resource type, and
+// the resource ID and relationship name where they apply.
+type Target struct {
+	Kind         TargetKind
+	Type         string
+	ID           string
+	Relationship string
+}
+
+// Methods returns the HTTP methods the specification defines for the kind of the target.
+func (t Target) Methods() []string {
+	switch t.Kind {
+	case CollectionTarget:
+		return []string{http.MethodGet, http.MethodPost}
+	case ResourceTarget:
+		return []string{http.MethodGet, http.MethodPatch, http.MethodDelete}
+	case RelatedTarget:
+		return []string{http.MethodGet}
+	case RelationshipTarget:
+		return []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete}
+	}
+	return nil
+}
+
+// Allow returns nil if method is one of the allowed methods, or those returned by Methods if none are given.
+// Otherwise it returns a *MethodNotAllowedError listing the allowed methods.
+func (t Target) Allow(method string, allowed ...string) error {
+	if len(allowed) == 0 {
+		allowed = t.Methods()
+	}
+	if containsString(allowed, method) {
+		return nil
+	}
+	return &MethodNotAllowedError{Method: method, Allow: allowed}
+}
+
+// Path returns the path of the target relative to the root of the API, with each segment escaped, e.g.
+// "/articles/1/relationships/author".
+func (t Target) Path() string {
+	segments := []string{t.Type}
+	switch t.Kind {
+	case ResourceTarget:
+		segments = append(segments, t.ID)
+	case RelatedTarget:
+		segments = append(segments, t.ID, t.Relationship)
+	case RelationshipTarget:
+		segments = append(segments, t.ID, "relationships", t.Relationship)
+	}
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// MethodNotAllowedError is returned when the method of a request is not allowed for its target. Servers should
+// respond with a 405 Method Not Allowed error, which the error unwraps to, and set the "Allow" header, e.g. with
+// SetHeader.
+type MethodNotAllowedError struct {
+	Method string
+	Allow  []string
+}
+
+// Error returns a message holding the method and the allowed methods.
+func (e *MethodNotAllowedError) Error() string {
+	return fmt.Sprintf("%s: method %s is not allowed, allowed methods are %s", packageName, e.Method,
+		strings.Join(e.Allow, ", "))
+}
+
+// Unwrap returns the 405 Method Not Allowed *Error sent to the client.
+func (e *MethodNotAllowedError) Unwrap() error {
+	return &Error{
+		Status: fmt.Sprint(http.StatusMethodNotAllowed),
+		Title:  http.StatusText(http.StatusMethodNotAllowed),
+		Detail: fmt.Sprintf("method %s is not allowed", e.Method),
+	}
+}
+
+// SetHeader sets the "Allow" header of the response header h to the allowed methods.
+func (e *MethodNotAllowedError) SetHeader(h http.Header) {
+	h.Set("Allow", strings.Join(e.Allow, ", "))
+}
+
+// PathParser parses the request paths of the endpoints defined by the specification into Targets, independent
+// of the router used:
+//
+//	/{type}
+//	/{type}/{id}
+//	/{type}/{id}/{relationship}
+//	/{type}/{id}/relationships/{relationship}
+//
+// Its zero value parses paths at the root of the host, and accepts any resource type.
+type PathParser struct {
+	// BasePath is the path the API is mounted at, e.g. "/api". It is stripped from parsed paths, unless Prefix
+	// is set, and prepended to the paths returned by Path.
+	BasePath string
+
+	// Prefix, if set, is stripped from parsed paths instead of BasePath, e.g. "/" if a router already stripped
+	// the mount path, or "/v1" if a proxy serves the API at BasePath "/api" and forwards requests to "/v1".
+	Prefix string
+
+	// IsType reports whether a resource type is served. If nil, all types are served.
+	IsType func(typ string) bool
+}
+
+// Parse parses the escaped request path, as returned by url.URL.EscapedPath, so IDs may hold encoded slashes.
+//
+// It returns a 404 Not Found *Error if the path is outside of the API, does not match any endpoint or refers to
+// a resource type which is not served.
+func (p *PathParser) Parse(path string) (Target, error) {
+	prefix := p.Prefix
+	if len(prefix) == 0 {
+		prefix = p.BasePath
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+		return Target{}, pathNotFound(path)
+	}
+
+	rest := strings.Trim(strings.TrimPrefix(path, prefix), "/")
+	segments := strings.Split(rest, "/")
+	for i, s := range segments {
+		unescaped, err := url.PathUnescape(s)
+		if err != nil || len(unescaped) == 0 {
+			return Target{}, pathNotFound(path)
+		}
+		segments[i] = unescaped
+	}
+
+	t := Target{Type: segments[0]}
+	switch len(segments) {
+	case 1:
+		t.Kind = CollectionTarget
+	case 2:
+		t.Kind, t.ID = ResourceTarget, segments[1]
+	case 3:
+		if segments[2] == "relationships" {
+			return Target{}, pathNotFound(path)
+		}
+		t.Kind, t.ID, t.Relationship = RelatedTarget, segments[1], segments[2]
+	case 4:
+		if segments[2] != "relationships" {
+			return Target{}, pathNotFound(path)
+		}
+		t.Kind, t.ID, t.Relationship = RelationshipTarget, segments[1], segments[3]
+	default:
+		return Target{}, pathNotFound(path)
+	}
+
+	if p.IsType != nil && !p.IsType(t.Type) {
+		return Target{}, pathNotFound(path)
+	}
+	return t, nil
+}
+
+// ParseRequest parses the path of the request r, see Parse, and checks whether its method is allowed for the
+// target, see Target.Allow.
+func (p *PathParser) ParseRequest(r *http.Request) (Target, error) {
+	t, err := p.Parse(r.URL.EscapedPath())
+	if err != nil {
+		return Target{}, err
+	}
+	if err := t.Allow(r.Method); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+// Path returns the path of the target t, prefixed with BasePath.
+func (p *PathParser) Path(t Target) string {
+	return strings.TrimSuffix(p.BasePath, "/") + t.Path()
+}
+
+// helpers
+
+func pathNotFound(path string) *Error {
+	return &Error{
+		Status: fmt.Sprint(http.StatusNotFound),
+		Title:  http.StatusText(http.StatusNotFound),
+		Detail: fmt.Sprintf("no endpoint exists at path %q", path),
+	}
+}
@@ -0,0 +1,118 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+func TestPathParser_Parse(t *testing.T) {
+	p := jsonapi.PathParser{
+		BasePath: "/api",
+		IsType:   func(typ string) bool { return typ != "unknown" },
+	}
+
+	tests := []struct {
+		path   string
+		target jsonapi.Target
+	}{
+		{"/api/articles", jsonapi.Target{Kind: jsonapi.CollectionTarget, Type: "articles"}},
+		{"/api/articles/", jsonapi.Target{Kind: jsonapi.CollectionTarget, Type: "articles"}},
+		{"/api/articles/1", jsonapi.Target{Kind: jsonapi.ResourceTarget, Type: "articles", ID: "1"}},
+		{"/api/articles/a%2Fb", jsonapi.Target{Kind: jsonapi.ResourceTarget, Type: "articles", ID: "a/b"}},
+		{"/api/articles/1/author", jsonapi.Target{
+			Kind: jsonapi.RelatedTarget, Type: "articles", ID: "1", Relationship: "author",
+		}},
+		{"/api/articles/1/relationships/author", jsonapi.Target{
+			Kind: jsonapi.RelationshipTarget, Type: "articles", ID: "1", Relationship: "author",
+		}},
+	}
+
+	for _, test := range tests {
+		target, err := p.Parse(test.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error from Parse: %+v", test.path, err)
+			continue
+		}
+		if target != test.target {
+			t.Errorf("%s: unexpected target, expected: %+v, actual: %+v", test.path, test.target, target)
+		}
+		if path := p.Path(target); path != "/api"+target.Path() {
+			t.Errorf("%s: unexpected path of target: %s", test.path, path)
+		}
+	}
+
+	for _, path := range []string{
+		"/articles",
+		"/apiarticles",
+		"/api",
+		"/api/unknown",
+		"/api/articles//author",
+		"/api/articles/1/relationships",
+		"/api/articles/1/links/author",
+		"/api/articles/1/relationships/author/extra",
+	} {
+		_, err := p.Parse(path)
+		if e, ok := err.(*jsonapi.Error); !ok || e.Status != "404" {
+			t.Errorf("%s: expected 404 error from Parse, actual: %+v", path, err)
+		}
+	}
+}
+
+func TestPathParser_Prefix(t *testing.T) {
+	p := jsonapi.PathParser{BasePath: "/api", Prefix: "/"}
+
+	target, err := p.Parse("/articles/1")
+	if err != nil {
+		t.Fatalf("unexpected error from Parse: %+v", err)
+	}
+	if path := p.Path(target); path != "/api/articles/1" {
+		t.Errorf("unexpected path of target: %s", path)
+	}
+}
+
+func TestTarget_Path(t *testing.T) {
+	target := jsonapi.Target{Kind: jsonapi.RelationshipTarget, Type: "articles", ID: "a/b", Relationship: "author"}
+	if path := target.Path(); path != "/articles/a%2Fb/relationships/author" {
+		t.Errorf("unexpected path: %s", path)
+	}
+}
+
+func TestPathParser_ParseRequest(t *testing.T) {
+	p := jsonapi.PathParser{}
+
+	r := httptest.NewRequest(http.MethodPatch, "/articles/1/relationships/tags", nil)
+	if _, err := p.ParseRequest(r); err != nil {
+		t.Errorf("unexpected error from ParseRequest: %+v", err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/articles/1", nil)
+	_, err := p.ParseRequest(r)
+
+	var mna *jsonapi.MethodNotAllowedError
+	if !errors.As(err, &mna) {
+		t.Fatalf("expected *MethodNotAllowedError from ParseRequest, actual: %+v", err)
+	}
+	h := http.Header{}
+	mna.SetHeader(h)
+	if allow := h.Get("Allow"); allow != "GET, PATCH, DELETE" {
+		t.Errorf("unexpected Allow header: %q", allow)
+	}
+	var e *jsonapi.Error
+	if !errors.As(err, &e) || e.Status != "405" {
+		t.Errorf("expected error to unwrap to a 405 error, actual: %+v", e)
+	}
+}
+
+func TestTarget_Allow(t *testing.T) {
+	target := jsonapi.Target{Kind: jsonapi.CollectionTarget, Type: "articles"}
+	if err := target.Allow(http.MethodPost, http.MethodGet); err == nil {
+		t.Error("expected error for method which is not allowed")
+	}
+	if err := target.Allow(http.MethodPost); err != nil {
+		t.Errorf("unexpected error from Allow: %+v", err)
+	}
+}
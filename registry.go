@@ -0,0 +1,234 @@
+package jsonapi
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// AttributeType declares the JSON type of the values of an attribute.
+type AttributeType int
+
+const (
+	// AnyType attributes may hold values of any JSON type, which is the default.
+	AnyType AttributeType = iota
+
+	// StringType attributes hold JSON strings.
+	StringType
+
+	// NumberType attributes hold JSON numbers.
+	NumberType
+
+	// IntegerType attributes hold JSON numbers without a fractional part.
+	IntegerType
+
+	// BooleanType attributes hold true or false.
+	BooleanType
+
+	// ObjectType attributes hold JSON objects.
+	ObjectType
+
+	// ArrayType attributes hold JSON arrays.
+	ArrayType
+)
+
+// String returns the name of the type as used by JSON Schema, or an empty string for AnyType.
+func (t AttributeType) String() string {
+	switch t {
+	case AnyType:
+		return ""
+	case StringType:
+		return "string"
+	case NumberType:
+		return "number"
+	case IntegerType:
+		return "integer"
+	case BooleanType:
+		return "boolean"
+	case ObjectType:
+		return "object"
+	case ArrayType:
+		return "array"
+	default:
+		return fmt.Sprintf("AttributeType(%d)", int(t))
+	}
+}
+
+// AttributeSchema declares an attribute of a resource type.
+type AttributeSchema struct {
+	Name     string
+	Type     AttributeType
+	Nullable bool
+
+	// Access declares how clients may use the attribute, e.g. ReadOnly.
+	Access AttributeAccess
+}
+
+// RelationshipSchema declares a relationship of a resource type.
+type RelationshipSchema struct {
+	Name   string
+	ToMany bool
+
+	// Types are the types of the related resources, which may be several for a polymorphic relationship.
+	Types []string
+}
+
+// ResourceSchema declares a resource type, i.e. its attributes and relationships, and how to construct values of
+// the custom type representing it.
+type ResourceSchema struct {
+	Type          string
+	Attributes    []AttributeSchema
+	Relationships []RelationshipSchema
+
+	// New returns a new, empty value of the custom type representing the resource type, which implements the
+	// adapter interfaces used by ToResource and FromResource.
+	New func() interface{}
+}
+
+// Attribute returns the declaration of the attribute name and an existence check.
+func (s *ResourceSchema) Attribute(name string) (AttributeSchema, bool) {
+	for _, a := range s.Attributes {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return AttributeSchema{}, false
+}
+
+// Relationship returns the declaration of the relationship name and an existence check.
+func (s *ResourceSchema) Relationship(name string) (RelationshipSchema, bool) {
+	for _, rel := range s.Relationships {
+		if rel.Name == name {
+			return rel, true
+		}
+	}
+	return RelationshipSchema{}, false
+}
+
+// AttributeAccess returns the access levels of the attributes which are not ReadWrite, in the form returned by
+// the attribute access adapter, see FromResource.
+func (s *ResourceSchema) AttributeAccess() map[string]AttributeAccess {
+	access := make(map[string]AttributeAccess)
+	for _, a := range s.Attributes {
+		if a.Access != ReadWrite {
+			access[a.Name] = a.Access
+		}
+	}
+	return access
+}
+
+// validate returns an error if the schema has no type, or if any of its fields is unnamed, named "id" or "type",
+// or shares its name with another field, as the specification requires for the fields of a resource object.
+func (s *ResourceSchema) validate() error {
+	if len(s.Type) == 0 {
+		return errSchemaType
+	}
+
+	names := map[string]bool{"id": true, "type": true}
+	check := func(name string) error {
+		if len(name) == 0 || names[name] {
+			return fmt.Errorf("%s: invalid or duplicate field name %q in schema of type %q", packageName, name, s.Type)
+		}
+		names[name] = true
+		return nil
+	}
+	for _, a := range s.Attributes {
+		if err := check(a.Name); err != nil {
+			return err
+		}
+	}
+	for _, rel := range s.Relationships {
+		if err := check(rel.Name); err != nil {
+			return err
+		}
+		if len(rel.Types) == 0 {
+			return fmt.Errorf("%s: relationship %q of type %q has no target types", packageName, rel.Name, s.Type)
+		}
+	}
+	return nil
+}
+
+// Registry holds the schemas of the resource types known to an application. Its zero value is ready to use, and
+// it is safe for concurrent use.
+//
+// The schemas complement rather than replace the adapter interfaces: values are still converted with ToResource
+// and FromResource, while the registry describes which types exist and what they look like, e.g. to validate
+// include paths, to construct values by type or to generate documentation.
+type Registry struct {
+	mu      sync.RWMutex
+	schemas map[string]*ResourceSchema
+	graph   RelationshipGraph
+}
+
+// Register registers the schema s of a resource type. It returns an error if the type is already registered, or
+// the schema is invalid.
+func (reg *Registry) Register(s ResourceSchema) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if _, ok := reg.schemas[s.Type]; ok {
+		return fmt.Errorf("%s: type %q is already registered", packageName, s.Type)
+	}
+	if reg.schemas == nil {
+		reg.schemas = make(map[string]*ResourceSchema)
+	}
+	s.Attributes = append([]AttributeSchema(nil), s.Attributes...)
+	s.Relationships = append([]RelationshipSchema(nil), s.Relationships...)
+	reg.schemas[s.Type] = &s
+
+	reg.graph.AddType(s.Type)
+	for _, rel := range s.Relationships {
+		reg.graph.Add(s.Type, rel.Name, rel.Types...)
+	}
+	return nil
+}
+
+// Schema returns the schema of the resource type typ and an existence check. The schema must not be modified.
+func (reg *Registry) Schema(typ string) (*ResourceSchema, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	s, ok := reg.schemas[typ]
+	return s, ok
+}
+
+// Types returns the registered resource types, in alphabetical order.
+func (reg *Registry) Types() []string {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	types := make([]string, 0, len(reg.schemas))
+	for typ := range reg.schemas {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// New returns a new value of the custom type representing the resource type typ, or an error if the type is not
+// registered or has no constructor.
+func (reg *Registry) New(typ string) (interface{}, error) {
+	s, ok := reg.Schema(typ)
+	if !ok {
+		return nil, fmt.Errorf("%s: type %q is not registered", packageName, typ)
+	}
+	if s.New == nil {
+		return nil, fmt.Errorf("%s: type %q has no constructor", packageName, typ)
+	}
+	return s.New(), nil
+}
+
+// Relationships returns the relationship graph declared by the registered schemas, e.g. for an IncludeResolver.
+// Relationships should only be declared by registering schemas. Its limits may be set directly before the graph
+// is used, e.g. before a handler using it serves any requests, and only with SetLimits afterwards.
+func (reg *Registry) Relationships() *RelationshipGraph {
+	return &reg.graph
+}
+
+// errors
+
+var errSchemaType = fmt.Errorf("%s: schema has no type", packageName)
@@ -0,0 +1,161 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DecodeResource constructs a value of the custom type registered for the type of the resource r, and populates it
+// with FromResource using the options opts. This allows to decode resources whose type is only known at runtime,
+// e.g. those sent to an endpoint serving several types, or related by a polymorphic relationship.
+//
+// If the type of r is missing or not registered, DecodeResource returns Errors holding a 400 Bad Request or
+// 409 Conflict error with the source pointer "/data/type".
+func (reg *Registry) DecodeResource(r *Resource, opts ...FromResourceOption) (interface{}, error) {
+	return reg.decodeResource(r, "/data", opts)
+}
+
+// DecodeDocument decodes the primary data and included resources of the document doc, see DecodeResource.
+//
+// The primary data is returned as nil if it is null or missing, as a []interface{} if it is an array, and as the
+// single value otherwise. The options opts only apply to the primary data, as checks like ExpectType only concern
+// the resources sent to an endpoint.
+//
+// Errors of all resources are collected, with their source pointers referring to the resource they belong to,
+// e.g. "/data/1/type" or "/included/0/attributes/title".
+func (reg *Registry) DecodeDocument(doc *Document, opts ...FromResourceOption) (interface{}, []interface{}, error) {
+	var (
+		data interface{}
+		errs Errors
+	)
+	collect := func(err error) error {
+		var (
+			es Errors
+			e  *Error
+		)
+		switch {
+		case errors.As(err, &es):
+			errs = append(errs, es...)
+		case errors.As(err, &e):
+			errs = append(errs, *e)
+		default:
+			return err
+		}
+		return nil
+	}
+
+	switch jsonKind(doc.Data) {
+	case '[':
+		var rs []Resource
+		if err := json.Unmarshal(doc.Data, &rs); err != nil {
+			return nil, nil, err
+		}
+		vs := make([]interface{}, len(rs))
+		for i := range rs {
+			v, err := reg.decodeResource(&rs[i], fmt.Sprintf("/data/%d", i), opts)
+			if err != nil {
+				if err := collect(err); err != nil {
+					return nil, nil, err
+				}
+			}
+			vs[i] = v
+		}
+		data = vs
+
+	case '{':
+		r := Resource{}
+		if err := json.Unmarshal(doc.Data, &r); err != nil {
+			return nil, nil, err
+		}
+		v, err := reg.decodeResource(&r, "/data", opts)
+		if err != nil {
+			if err := collect(err); err != nil {
+				return nil, nil, err
+			}
+		}
+		data = v
+	}
+
+	included := make([]interface{}, len(doc.Included))
+	for i := range doc.Included {
+		v, err := reg.decodeResource(&doc.Included[i], fmt.Sprintf("/included/%d", i), nil)
+		if err != nil {
+			if err := collect(err); err != nil {
+				return nil, nil, err
+			}
+		}
+		included[i] = v
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+	return data, included, nil
+}
+
+// decodeResource decodes the resource r found at the JSON pointer p, rewriting the source pointers of any errors
+// from "/data" to p.
+func (reg *Registry) decodeResource(r *Resource, p string, opts []FromResourceOption) (interface{}, error) {
+	if len(r.Type) == 0 {
+		return nil, Errors{{
+			Status: fmt.Sprint(http.StatusBadRequest),
+			Title:  "Missing type",
+			Detail: "resource must have a type",
+			Source: &ErrorSource{Pointer: p + "/type"},
+		}}
+	}
+	if _, ok := reg.Schema(r.Type); !ok {
+		return nil, Errors{{
+			Status: fmt.Sprint(http.StatusConflict),
+			Title:  "Unsupported type",
+			Detail: fmt.Sprintf("resource type %q is not supported", r.Type),
+			Source: &ErrorSource{Pointer: p + "/type"},
+		}}
+	}
+
+	v, err := reg.New(r.Type)
+	if err != nil {
+		return nil, err
+	}
+	if err := FromResource(v, r, true, opts...); err != nil {
+		return nil, withPointerPrefix(err, p)
+	}
+	return v, nil
+}
+
+// helpers
+
+// withPointerPrefix returns err with the source pointers of its JSON API errors rebased from "/data" to p.
+func withPointerPrefix(err error, p string) error {
+	if p == "/data" {
+		return err
+	}
+	rebase := func(e Error) Error {
+		if e.Source != nil && (e.Source.Pointer == "/data" || strings.HasPrefix(e.Source.Pointer, "/data/")) {
+			source := *e.Source
+			source.Pointer = p + strings.TrimPrefix(source.Pointer, "/data")
+			e.Source = &source
+		}
+		return e
+	}
+
+	var (
+		es Errors
+		e  *Error
+	)
+	switch {
+	case errors.As(err, &es):
+		rebased := make(Errors, len(es))
+		for i := range es {
+			rebased[i] = rebase(es[i])
+		}
+		return rebased
+	case errors.As(err, &e):
+		rebased := rebase(*e)
+		return &rebased
+	}
+	return err
+}
@@ -0,0 +1,154 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+type testCommentable struct {
+	Type, ID, Title string
+	Access          map[string]jsonapi.AttributeAccess
+}
+
+func (c *testCommentable) SetID(id string) error    { c.ID = id; return nil }
+func (c *testCommentable) SetType(typ string) error { c.Type = typ; return nil }
+func (c *testCommentable) GetID() (string, error)   { return c.ID, nil }
+func (c *testCommentable) GetType() (string, error) { return c.Type, nil }
+
+func (c *testCommentable) GetAttributeAccess() map[string]jsonapi.AttributeAccess {
+	return c.Access
+}
+
+func (c *testCommentable) SetAttributes(as map[string]interface{}) error {
+	c.Title, _ = jsonapi.Attributes(as).String("title")
+	return nil
+}
+
+func newTestDecodeRegistry(t *testing.T) *jsonapi.Registry {
+	reg := &jsonapi.Registry{}
+	for _, typ := range []string{"articles", "photos"} {
+		err := reg.Register(jsonapi.ResourceSchema{
+			Type:       typ,
+			Attributes: []jsonapi.AttributeSchema{{Name: "title", Type: jsonapi.StringType}},
+			New: func() interface{} {
+				return &testCommentable{Access: map[string]jsonapi.AttributeAccess{"views": jsonapi.ReadOnly}}
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error from Register: %+v", err)
+		}
+	}
+	return reg
+}
+
+func TestRegistry_DecodeResource(t *testing.T) {
+	reg := newTestDecodeRegistry(t)
+
+	v, err := reg.DecodeResource(&jsonapi.Resource{
+		Type:       "photos",
+		ID:         "1",
+		Attributes: jsonapi.Attributes{"title": "foo"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error from DecodeResource: %+v", err)
+	}
+	if c, ok := v.(*testCommentable); !ok || c.Type != "photos" || c.ID != "1" || c.Title != "foo" {
+		t.Errorf("unexpected value from DecodeResource: %+v", v)
+	}
+
+	tests := []struct {
+		r      jsonapi.Resource
+		status string
+	}{
+		{jsonapi.Resource{}, "400"},
+		{jsonapi.Resource{Type: "videos"}, "409"},
+	}
+	for _, test := range tests {
+		_, err := reg.DecodeResource(&test.r)
+		es, ok := err.(jsonapi.Errors)
+		if !ok || es[0].Status != test.status || es[0].Source.Pointer != "/data/type" {
+			t.Errorf("%+v: unexpected error from DecodeResource: %+v", test.r, err)
+		}
+	}
+}
+
+func TestRegistry_DecodeDocument(t *testing.T) {
+	reg := newTestDecodeRegistry(t)
+
+	doc := jsonapi.Document{}
+	err := json.Unmarshal([]byte(`{
+		"data": [
+			{"type": "articles", "id": "1", "attributes": {"title": "foo"}},
+			{"type": "photos", "id": "2", "attributes": {"title": "bar"}}
+		],
+		"included": [
+			{"type": "photos", "id": "3"}
+		]
+	}`), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, included, err := reg.DecodeDocument(&doc)
+	if err != nil {
+		t.Fatalf("unexpected error from DecodeDocument: %+v", err)
+	}
+	vs, ok := data.([]interface{})
+	if !ok || len(vs) != 2 {
+		t.Fatalf("unexpected primary data: %#v", data)
+	}
+	if c := vs[1].(*testCommentable); c.Type != "photos" || c.Title != "bar" {
+		t.Errorf("unexpected primary resource: %+v", c)
+	}
+	if len(included) != 1 || included[0].(*testCommentable).ID != "3" {
+		t.Errorf("unexpected included resources: %+v", included)
+	}
+
+	doc = jsonapi.Document{Data: []byte(`{"type": "photos", "id": "2"}`)}
+	if data, _, err := reg.DecodeDocument(&doc); err != nil || data.(*testCommentable).ID != "2" {
+		t.Errorf("unexpected result from DecodeDocument: %+v, %+v", data, err)
+	}
+
+	doc = jsonapi.Document{Data: []byte(`null`)}
+	if data, _, err := reg.DecodeDocument(&doc); err != nil || data != nil {
+		t.Errorf("unexpected result from DecodeDocument: %+v, %+v", data, err)
+	}
+}
+
+func TestRegistry_DecodeDocument_Errors(t *testing.T) {
+	reg := newTestDecodeRegistry(t)
+
+	doc := jsonapi.Document{}
+	err := json.Unmarshal([]byte(`{
+		"data": [
+			{"type": "articles", "attributes": {"views": 1}},
+			{"type": "videos"}
+		],
+		"included": [
+			{"type": "articles", "id": "3", "attributes": {"views": 1}},
+			{"type": "videos", "id": "4"}
+		]
+	}`), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = reg.DecodeDocument(&doc, jsonapi.ForCreate())
+	es, ok := err.(jsonapi.Errors)
+	if !ok {
+		t.Fatalf("expected Errors from DecodeDocument, actual: %+v", err)
+	}
+
+	// the options only apply to primary data, so the read-only attribute of the included resource is accepted
+	pointers := []string{"/data/0/attributes/views", "/data/1/type", "/included/1/type"}
+	if len(es) != len(pointers) {
+		t.Fatalf("unexpected errors: %+v", es)
+	}
+	for i, p := range pointers {
+		if es[i].Source == nil || es[i].Source.Pointer != p {
+			t.Errorf("unexpected source of error %d, expected: %s, actual: %+v", i, p, es[i].Source)
+		}
+	}
+}
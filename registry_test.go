@@ -0,0 +1,153 @@
+package jsonapi_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+type testRegistryArticle struct {
+	ID    string
+	Title string
+}
+
+func newTestRegistry(t *testing.T) *jsonapi.Registry {
+	reg := &jsonapi.Registry{}
+	schemas := []jsonapi.ResourceSchema{
+		{
+			Type: "articles",
+			Attributes: []jsonapi.AttributeSchema{
+				{Name: "title", Type: jsonapi.StringType},
+				{Name: "views", Type: jsonapi.IntegerType, Access: jsonapi.ReadOnly},
+				{Name: "summary", Type: jsonapi.StringType, Nullable: true},
+			},
+			Relationships: []jsonapi.RelationshipSchema{
+				{Name: "author", Types: []string{"people"}},
+				{Name: "comments", ToMany: true, Types: []string{"comments"}},
+			},
+			New: func() interface{} { return &testRegistryArticle{} },
+		},
+		{
+			Type: "people",
+			Attributes: []jsonapi.AttributeSchema{
+				{Name: "name", Type: jsonapi.StringType},
+				{Name: "password", Type: jsonapi.StringType, Access: jsonapi.WriteOnly},
+			},
+		},
+		{
+			Type: "comments",
+			Relationships: []jsonapi.RelationshipSchema{
+				{Name: "author", Types: []string{"people"}},
+			},
+		},
+	}
+	for _, s := range schemas {
+		if err := reg.Register(s); err != nil {
+			t.Fatalf("unexpected error from Register: %+v", err)
+		}
+	}
+	return reg
+}
+
+func TestRegistry_Register(t *testing.T) {
+	reg := newTestRegistry(t)
+
+	invalid := []jsonapi.ResourceSchema{
+		{},
+		{Type: "articles"},
+		{Type: "photos", Attributes: []jsonapi.AttributeSchema{{Name: "id"}}},
+		{Type: "photos", Attributes: []jsonapi.AttributeSchema{{}}},
+		{
+			Type:          "photos",
+			Attributes:    []jsonapi.AttributeSchema{{Name: "author"}},
+			Relationships: []jsonapi.RelationshipSchema{{Name: "author", Types: []string{"people"}}},
+		},
+		{Type: "photos", Relationships: []jsonapi.RelationshipSchema{{Name: "author"}}},
+	}
+	for _, s := range invalid {
+		if err := reg.Register(s); err == nil {
+			t.Errorf("expected error registering schema: %+v", s)
+		}
+	}
+
+	if types := reg.Types(); !reflect.DeepEqual(types, []string{"articles", "comments", "people"}) {
+		t.Errorf("unexpected types: %v", types)
+	}
+}
+
+func TestRegistry_Schema(t *testing.T) {
+	reg := newTestRegistry(t)
+
+	s, ok := reg.Schema("articles")
+	if !ok {
+		t.Fatal("expected schema of articles to exist")
+	}
+	if a, ok := s.Attribute("views"); !ok || a.Type != jsonapi.IntegerType || a.Type.String() != "integer" {
+		t.Errorf("unexpected attribute: %+v", a)
+	}
+	if rel, ok := s.Relationship("comments"); !ok || !rel.ToMany {
+		t.Errorf("unexpected relationship: %+v", rel)
+	}
+	if _, ok := s.Attribute("author"); ok {
+		t.Error("expected relationship not to be found as attribute")
+	}
+	if access := s.AttributeAccess(); !reflect.DeepEqual(access, map[string]jsonapi.AttributeAccess{"views": jsonapi.ReadOnly}) {
+		t.Errorf("unexpected attribute access: %v", access)
+	}
+	if _, ok := reg.Schema("photos"); ok {
+		t.Error("expected schema of unregistered type not to exist")
+	}
+}
+
+func TestRegistry_New(t *testing.T) {
+	reg := newTestRegistry(t)
+
+	v, err := reg.New("articles")
+	if _, ok := v.(*testRegistryArticle); !ok || err != nil {
+		t.Errorf("unexpected value from New: %#v, %+v", v, err)
+	}
+	if _, err := reg.New("people"); err == nil {
+		t.Error("expected error for type without constructor")
+	}
+	if _, err := reg.New("photos"); err == nil {
+		t.Error("expected error for unregistered type")
+	}
+}
+
+func TestRegistry_Relationships(t *testing.T) {
+	reg := newTestRegistry(t)
+	g := reg.Relationships()
+
+	if err := g.ValidateInclude("articles", []string{"comments.author", "author"}); err != nil {
+		t.Errorf("unexpected error from ValidateInclude: %+v", err)
+	}
+	if err := g.ValidateInclude("people", []string{"articles"}); err == nil {
+		t.Error("expected error for undeclared relationship")
+	}
+}
+
+func TestRegistry_Relationships_SetLimits(t *testing.T) {
+	reg := newTestRegistry(t)
+	g := reg.Relationships()
+
+	// limits may be changed while the graph is in use
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.ValidateInclude("articles", []string{"comments.author"})
+		}()
+	}
+	g.SetLimits(1, 10)
+	wg.Wait()
+
+	if err := g.ValidateInclude("articles", []string{"comments.author"}); err == nil {
+		t.Error("expected error for include path nested deeper than MaxDepth")
+	}
+	if g.MaxIncluded != 10 {
+		t.Errorf("unexpected MaxIncluded: %d", g.MaxIncluded)
+	}
+}
@@ -0,0 +1,169 @@
+package jsonapi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// RelationshipOp represents the kind of update a client requests by sending a document to a relationship
+// endpoint, such as "/articles/1/relationships/tags".
+//
+// http://jsonapi.org/format/#crud-updating-relationships
+type RelationshipOp int
+
+// The relationship updates defined by the specification.
+const (
+	RelationshipReplace RelationshipOp = iota // PATCH: replace the relationship's data
+	RelationshipAdd                           // POST: add members to a to-many relationship
+	RelationshipRemove                        // DELETE: remove members from a to-many relationship
+)
+
+// RelationshipOpForMethod returns the relationship update requested by the HTTP method and an existence check.
+func RelationshipOpForMethod(method string) (RelationshipOp, bool) {
+	switch method {
+	case http.MethodPatch:
+		return RelationshipReplace, true
+	case http.MethodPost:
+		return RelationshipAdd, true
+	case http.MethodDelete:
+		return RelationshipRemove, true
+	}
+	return 0, false
+}
+
+// String returns the HTTP method of the relationship update.
+func (op RelationshipOp) String() string {
+	switch op {
+	case RelationshipAdd:
+		return http.MethodPost
+	case RelationshipRemove:
+		return http.MethodDelete
+	default:
+		return http.MethodPatch
+	}
+}
+
+// DecodeRelationshipRequest reads and validates the document sent by a client to a relationship endpoint for
+// the relationship update op, which may only hold resource identifier objects as its primary data: an array for
+// RelationshipAdd and RelationshipRemove, and additionally a single one or null for RelationshipReplace. Members
+// of the extensions with the given namespaces are allowed, as for DisallowUnknownMembers, as well as the
+// "jsonapi", "links" and "meta" members of the document; the latter becomes the Meta of the returned Relationship.
+//
+// It returns Errors holding a 400 Bad Request error for each problem found, with a source pointer such as
+// "/data/0/type".
+func DecodeRelationshipRequest(r io.Reader, op RelationshipOp, namespaces ...string) (*Relationship, error) {
+	dec := NewDecoder(r)
+	dec.Strict()
+	dec.DisallowUnknownMembers(namespaces...)
+	dec.d.identifiers = true
+
+	doc := Document{}
+	if err := dec.Decode(&doc); err != nil {
+		if _, ok := err.(Errors); ok {
+			return nil, err
+		}
+		return nil, Errors{{
+			Status: fmt.Sprint(http.StatusBadRequest),
+			Title:  "Invalid document",
+			Detail: err.Error(),
+		}}
+	}
+
+	switch kind := jsonKind(doc.Data); {
+	case kind == 0:
+		return nil, Errors{relationshipRequestError("Missing member", `document must contain "data"`, "")}
+	case kind != '[' && op != RelationshipReplace:
+		return nil, Errors{relationshipRequestError("Invalid member",
+			fmt.Sprintf("data must be an array of resource identifier objects for %s requests", op), "/data")}
+	case doc.Included != nil:
+		return nil, Errors{relationshipRequestError("Invalid member",
+			`document sent to a relationship endpoint must not contain "included"`, "/included")}
+	}
+	return &Relationship{Data: doc.Data, Meta: doc.Meta}, nil
+}
+
+// UpdateRelationship uses the adapter implementation v to apply the relationship update op to the relationship
+// name, with the resource identifiers of rel as sent by the client, see DecodeRelationshipRequest.
+//
+// Several adapter interfaces are used to update relationships, one for each kind of update; all of them are
+// optional.
+//
+//	type relationshipReplaceAdapter interface {
+//		ReplaceRelationship(name string, rel *jsonapi.Relationship) error
+//	}
+//
+// The relationship replace adapter is used to replace the data of a to-one or to-many relationship. If it is not
+// implemented, UpdateRelationship returns a 403 Forbidden *Error, as the specification requires when full
+// replacement of a relationship is not allowed.
+//
+//	type relationshipAddAdapter interface {
+//		AddToRelationship(name string, ris []jsonapi.ResourceIdentifier) error
+//	}
+//
+// The relationship add adapter is used to add members to a to-many relationship. Members which are already part
+// of the relationship must not be added again.
+//
+//	type relationshipRemoveAdapter interface {
+//		RemoveFromRelationship(name string, ris []jsonapi.ResourceIdentifier) error
+//	}
+//
+// The relationship remove adapter is used to remove members from a to-many relationship. Members which are not
+// part of the relationship must be ignored.
+//
+// If the relationship add or remove adapter is not implemented, UpdateRelationship returns a 403 Forbidden *Error
+// as well. Any of the adapters may return a 403 Forbidden *Error themselves, e.g. if the relationship is to-one,
+// or a client is not allowed to update it.
+func UpdateRelationship(v interface{}, name string, op RelationshipOp, rel *Relationship) error {
+	switch op {
+	case RelationshipReplace:
+		if v, ok := v.(relationshipReplaceAdapter); ok {
+			return v.ReplaceRelationship(name, rel)
+		}
+		return relationshipForbidden(name, "full replacement of relationship %q is not allowed")
+
+	case RelationshipAdd:
+		if v, ok := v.(relationshipAddAdapter); ok {
+			ris, err := rel.ToMany()
+			if err != nil {
+				return err
+			}
+			return v.AddToRelationship(name, ris)
+		}
+		return relationshipForbidden(name, "members cannot be added to relationship %q")
+
+	case RelationshipRemove:
+		if v, ok := v.(relationshipRemoveAdapter); ok {
+			ris, err := rel.ToMany()
+			if err != nil {
+				return err
+			}
+			return v.RemoveFromRelationship(name, ris)
+		}
+		return relationshipForbidden(name, "members cannot be removed from relationship %q")
+	}
+
+	return fmt.Errorf("%s: unknown relationship update %d", packageName, op)
+}
+
+// helpers
+
+func relationshipRequestError(title, detail, pointer string) Error {
+	e := Error{
+		Status: fmt.Sprint(http.StatusBadRequest),
+		Title:  title,
+		Detail: detail,
+	}
+	if len(pointer) > 0 {
+		e.Source = &ErrorSource{Pointer: pointer}
+	}
+	return e
+}
+
+func relationshipForbidden(name, format string) *Error {
+	return &Error{
+		Status: fmt.Sprint(http.StatusForbidden),
+		Title:  "Forbidden",
+		Detail: fmt.Sprintf(format, name),
+	}
+}
@@ -0,0 +1,133 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+type tagsAdapter struct {
+	name    string
+	added   []jsonapi.ResourceIdentifier
+	removed []jsonapi.ResourceIdentifier
+}
+
+func (a *tagsAdapter) AddToRelationship(name string, ris []jsonapi.ResourceIdentifier) error {
+	a.name, a.added = name, ris
+	return nil
+}
+
+func (a *tagsAdapter) RemoveFromRelationship(name string, ris []jsonapi.ResourceIdentifier) error {
+	a.name, a.removed = name, ris
+	return nil
+}
+
+func TestRelationshipOpForMethod(t *testing.T) {
+	for _, method := range []string{http.MethodPatch, http.MethodPost, http.MethodDelete} {
+		op, ok := jsonapi.RelationshipOpForMethod(method)
+		if !ok || op.String() != method {
+			t.Errorf("unexpected relationship update for method %s: %v, %v", method, op, ok)
+		}
+	}
+	if _, ok := jsonapi.RelationshipOpForMethod(http.MethodGet); ok {
+		t.Error("expected no relationship update for method GET")
+	}
+}
+
+func TestDecodeRelationshipRequest(t *testing.T) {
+	tests := []struct {
+		body     string
+		op       jsonapi.RelationshipOp
+		pointers []string
+	}{
+		{`{"data": [{"type": "tags", "id": "1"}]}`, jsonapi.RelationshipAdd, nil},
+		{`{"data": null}`, jsonapi.RelationshipReplace, nil},
+		{`{"data": {"type": "people", "id": "1"}}`, jsonapi.RelationshipReplace, nil},
+		{`{"data": {"type": "tags", "id": "1"}}`, jsonapi.RelationshipRemove, []string{"/data"}},
+		{`{"data": null}`, jsonapi.RelationshipAdd, []string{"/data"}},
+		{`{"meta": {}}`, jsonapi.RelationshipAdd, []string{""}},
+		{`{"data": [{"type": "tags"}, {"id": "2", "type": 2}]}`, jsonapi.RelationshipAdd, []string{"/data/0", "/data/1/type"}},
+		{`{"data": [], "included": []}`, jsonapi.RelationshipReplace, []string{"/included"}},
+		{`[`, jsonapi.RelationshipAdd, []string{""}},
+		{`{"data": [], "jsonapi": {"version": "1.1"}, "meta": {"a": 1}}`, jsonapi.RelationshipReplace, nil},
+		{`{"data": [{"type": "tags", "id": "1", "attributes": {}}]}`, jsonapi.RelationshipAdd,
+			[]string{"/data/0/attributes"}},
+		{`{"data": [], "version:id": "1", "foo": 1}`, jsonapi.RelationshipAdd, []string{"/version:id", "/foo"}},
+	}
+
+	for _, test := range tests {
+		rel, err := jsonapi.DecodeRelationshipRequest(strings.NewReader(test.body), test.op)
+		if test.pointers == nil {
+			if err != nil || rel == nil {
+				t.Errorf("%s: unexpected error from DecodeRelationshipRequest: %+v", test.body, err)
+			}
+			continue
+		}
+
+		errs, ok := err.(jsonapi.Errors)
+		if !ok || len(errs) != len(test.pointers) {
+			t.Errorf("%s: expected %d errors, actual: %+v", test.body, len(test.pointers), err)
+			continue
+		}
+		for i, e := range errs {
+			pointer := ""
+			if e.Source != nil {
+				pointer = e.Source.Pointer
+			}
+			if e.Status != "400" || pointer != test.pointers[i] {
+				t.Errorf("%s: unexpected error, expected pointer: %q, actual: %+v", test.body, test.pointers[i], e)
+			}
+		}
+	}
+}
+
+func TestDecodeRelationshipRequest_Namespaces(t *testing.T) {
+	body := `{"data": [{"type": "tags", "id": "1"}], "version:id": "1", "meta": {"a": "b"}}`
+	rel, err := jsonapi.DecodeRelationshipRequest(strings.NewReader(body), jsonapi.RelationshipAdd, "version")
+	if err != nil {
+		t.Fatalf("unexpected error from DecodeRelationshipRequest: %+v", err)
+	}
+	if ris, _ := rel.ToMany(); len(ris) != 1 || ris[0].ID != "1" {
+		t.Errorf("unexpected relationship data: %s", rel.Data)
+	}
+	if v, _ := rel.Meta.GetString("a"); v != "b" {
+		t.Errorf("expected meta of the document to be kept, actual: %+v", rel.Meta)
+	}
+}
+
+func TestUpdateRelationship(t *testing.T) {
+	rel, err := jsonapi.DecodeRelationshipRequest(strings.NewReader(`{"data": [{"type": "tags", "id": "1"}]}`),
+		jsonapi.RelationshipAdd)
+	if err != nil {
+		t.Fatalf("unexpected error from DecodeRelationshipRequest: %+v", err)
+	}
+	expected := []jsonapi.ResourceIdentifier{{Type: "tags", ID: "1"}}
+
+	a := &tagsAdapter{}
+	if err := jsonapi.UpdateRelationship(a, "tags", jsonapi.RelationshipAdd, rel); err != nil {
+		t.Fatalf("unexpected error from UpdateRelationship: %+v", err)
+	}
+	if a.name != "tags" || !reflect.DeepEqual(a.added, expected) {
+		t.Errorf("unexpected members added to relationship %q: %+v", a.name, a.added)
+	}
+
+	if err := jsonapi.UpdateRelationship(a, "tags", jsonapi.RelationshipRemove, rel); err != nil {
+		t.Fatalf("unexpected error from UpdateRelationship: %+v", err)
+	}
+	if !reflect.DeepEqual(a.removed, expected) {
+		t.Errorf("unexpected members removed from relationship: %+v", a.removed)
+	}
+
+	// the adapter does not allow full replacement
+	var e *jsonapi.Error
+	if err := jsonapi.UpdateRelationship(a, "tags", jsonapi.RelationshipReplace, rel); !errors.As(err, &e) || e.Status != "403" {
+		t.Errorf("expected 403 error when replacing relationship is not supported, actual: %+v", err)
+	}
+	if err := jsonapi.UpdateRelationship(struct{}{}, "tags", jsonapi.RelationshipAdd, rel); !errors.As(err, &e) || e.Status != "403" {
+		t.Errorf("expected 403 error when adding to relationship is not supported, actual: %+v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package jsonapi
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // Relationships represents a JSON API relationships object
 //
@@ -9,9 +12,9 @@ type Relationships map[string]*Relationship
 
 // Relationship represents a member of the JSON API relationships object.
 type Relationship struct {
-	Links Links                  `json:"links,omitempty"`
-	Data  json.RawMessage        `json:"data,omitempty"`
-	Meta  map[string]interface{} `json:"meta,omitempty"`
+	Links Links           `json:"links,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Meta  Meta            `json:"meta,omitempty"`
 }
 
 // Add adds the key, value pair to the meta object.
@@ -39,3 +42,72 @@ func (rs Relationships) Delete(key string) {
 	}
 	delete(rs, key)
 }
+
+// IsToMany returns true if the relationship's data is an array of resource identifiers.
+func (r *Relationship) IsToMany() bool {
+	return jsonKind(r.Data) == '['
+}
+
+// ToOne decodes the relationship's data as the resource identifier of a to-one relationship.
+// It returns nil if the data is null or missing, and an error if it is not a single resource identifier.
+func (r *Relationship) ToOne() (*ResourceIdentifier, error) {
+	switch jsonKind(r.Data) {
+	case 0, 'n':
+		return nil, nil
+	case '{':
+		ri := &ResourceIdentifier{}
+		if err := json.Unmarshal(r.Data, ri); err != nil {
+			return nil, err
+		}
+		return ri, nil
+	default:
+		return nil, errRelationshipToOne
+	}
+}
+
+// ToMany decodes the relationship's data as the resource identifiers of a to-many relationship.
+// It returns nil if the data is missing, and an error if it is not an array of resource identifiers.
+func (r *Relationship) ToMany() ([]ResourceIdentifier, error) {
+	switch jsonKind(r.Data) {
+	case 0:
+		return nil, nil
+	case '[':
+		ris := []ResourceIdentifier{}
+		if err := json.Unmarshal(r.Data, &ris); err != nil {
+			return nil, err
+		}
+		return ris, nil
+	default:
+		return nil, errRelationshipToMany
+	}
+}
+
+// SetToOne sets the relationship's data to the resource identifier of a to-one relationship, or null if ri is nil.
+func (r *Relationship) SetToOne(ri *ResourceIdentifier) error {
+	b, err := json.Marshal(ri)
+	if err != nil {
+		return err
+	}
+	r.Data = b
+	return nil
+}
+
+// SetToMany sets the relationship's data to the resource identifiers of a to-many relationship.
+func (r *Relationship) SetToMany(ris []ResourceIdentifier) error {
+	if ris == nil {
+		ris = []ResourceIdentifier{}
+	}
+	b, err := json.Marshal(ris)
+	if err != nil {
+		return err
+	}
+	r.Data = b
+	return nil
+}
+
+// errors
+
+var (
+	errRelationshipToOne  = fmt.Errorf("%s: relationship data must be a resource identifier or null", packageName)
+	errRelationshipToMany = fmt.Errorf("%s: relationship data must be an array of resource identifiers", packageName)
+)
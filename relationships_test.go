@@ -1,6 +1,7 @@
 package jsonapi_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/smotes/jsonapi"
@@ -72,3 +73,52 @@ func TestRelationships_Delete(t *testing.T) {
 		t.Error("Relationships.Get should return nil/false after calling Relationships.Delete for the given key")
 	}
 }
+
+func TestRelationship_ToOne(t *testing.T) {
+	rel := jsonapi.Relationship{}
+	if ri, err := rel.ToOne(); ri != nil || err != nil {
+		t.Error("Relationship.ToOne should return nil/nil when data is missing")
+	}
+
+	expected := &jsonapi.ResourceIdentifier{Type: "people", ID: "42"}
+	if err := rel.SetToOne(expected); err != nil {
+		t.Fatalf("unexpected error from Relationship.SetToOne: %+v", err)
+	}
+	if rel.IsToMany() {
+		t.Error("Relationship.IsToMany should return false after Relationship.SetToOne")
+	}
+	if actual, err := rel.ToOne(); err != nil || !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected result from Relationship.ToOne, expected: %+v, actual: %+v", expected, actual)
+	}
+	if _, err := rel.ToMany(); err == nil {
+		t.Error("Relationship.ToMany should return an error for to-one relationships")
+	}
+
+	if err := rel.SetToOne(nil); err != nil || string(rel.Data) != "null" {
+		t.Errorf("Relationship.SetToOne should set null data for nil identifiers, actual: %s", rel.Data)
+	}
+	if ri, err := rel.ToOne(); ri != nil || err != nil {
+		t.Error("Relationship.ToOne should return nil/nil when data is null")
+	}
+}
+
+func TestRelationship_ToMany(t *testing.T) {
+	rel := jsonapi.Relationship{}
+	if err := rel.SetToMany(nil); err != nil || string(rel.Data) != "[]" {
+		t.Errorf("Relationship.SetToMany should set an empty array for nil identifiers, actual: %s", rel.Data)
+	}
+
+	expected := []jsonapi.ResourceIdentifier{{Type: "tags", ID: "1"}, {Type: "tags", Lid: "t2"}}
+	if err := rel.SetToMany(expected); err != nil {
+		t.Fatalf("unexpected error from Relationship.SetToMany: %+v", err)
+	}
+	if !rel.IsToMany() {
+		t.Error("Relationship.IsToMany should return true after Relationship.SetToMany")
+	}
+	if actual, err := rel.ToMany(); err != nil || !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected result from Relationship.ToMany, expected: %+v, actual: %+v", expected, actual)
+	}
+	if _, err := rel.ToOne(); err == nil {
+		t.Error("Relationship.ToOne should return an error for to-many relationships")
+	}
+}
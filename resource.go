@@ -5,8 +5,11 @@ import "fmt"
 // Resource represents a JSON API resource identifier or resource object.
 // Each valid JSON API resource object must contain at least the "id" and "type" keys,
 // and may contain the optional "attributes", "relationships", "links" and "meta" keys.
+// Resource objects sent by a client to create a resource may omit the "id" key, and may
+// instead contain a "lid" key identifying the resource within the request document.
 //
-// Note that any of the optional keys will be omitted if a value is not provided.
+// Note that any of the optional keys, as well as an empty "id", will be omitted if a value is not provided.
+// Any other members, such as those of extensions, are kept in Extra when decoding, see Members.
 //
 // For more information, see the specification at:
 //
@@ -14,12 +17,14 @@ import "fmt"
 //
 // http://jsonapi.org/format/#document-resource-identifier-objects
 type Resource struct {
-	ID            string                 `json:"id"`
-	Type          string                 `json:"type"`
-	Attributes    map[string]interface{} `json:"attributes,omitempty"`
-	Relationships Relationships          `json:"relationships,omitempty"`
-	Links         Links                  `json:"links,omitempty"`
-	Meta          map[string]interface{} `json:"meta,omitempty"`
+	ID            string        `json:"id,omitempty"`
+	Lid           string        `json:"lid,omitempty"`
+	Type          string        `json:"type"`
+	Attributes    Attributes    `json:"attributes,omitempty"`
+	Relationships Relationships `json:"relationships,omitempty"`
+	Links         Links         `json:"links,omitempty"`
+	Meta          Meta          `json:"meta,omitempty"`
+	Extra         Members       `json:"-"`
 }
 
 // ToResource uses the adapter implementation v to return the corresponding Resource.
@@ -67,6 +72,14 @@ type Resource struct {
 //
 // The meta read adapter is used to populate the Meta field on the resultant Resource.
 // It is optional and will be ignored if not implemented, or if it returns nil.
+//
+//	type attributeAccessAdapter interface {
+//		GetAttributeAccess() map[string]jsonapi.AttributeAccess
+//	}
+//
+// The attribute access adapter declares how clients may use each attribute of the custom type. Attributes
+// declared as WriteOnly, such as passwords, are omitted from the resultant Resource. It is optional and
+// all attributes are considered ReadWrite if not implemented.
 func ToResource(v interface{}, full bool) (*Resource, error) {
 	// each resource must have at least the "id" and "type" members
 	adapter, ok := v.(identityReadAdapter)
@@ -104,6 +117,9 @@ func ToResource(v interface{}, full bool) (*Resource, error) {
 			return nil, err
 		}
 	}
+	if v, ok := v.(attributeAccessAdapter); ok {
+		r.Attributes = withoutWriteOnly(v.GetAttributeAccess(), r.Attributes)
+	}
 	if v, ok := v.(relationshipsReadAdapter); ok {
 		r.Relationships, err = v.GetRelationships()
 		if err != nil {
@@ -156,12 +172,51 @@ func ToResource(v interface{}, full bool) (*Resource, error) {
 // Note the lack of a linksWriteAdapter and metaWriteAdapter, or a SetType method on the identityWriterAdapter. As per
 // the JSON API specification for client interaction with an API (including reading, creating, updating or deleting any
 // resources or relationships), these adapters should be unnecessary.
-func FromResource(adapter interface{}, r *Resource, full bool) error {
+//
+// When converting a resource sent by a client, the options ForCreate or ForUpdate enable checks against the
+// attribute access adapter (see ToResource), before any setters are called: attributes declared as ReadOnly,
+// or CreateOnly when updating, are rejected with Errors holding a 403 Forbidden error for each attribute,
+// with a source pointer such as "/data/attributes/created".
+//
+// The options ExpectType and ExpectID check the resource's identity against the endpoint it was sent to, and
+// reject any mismatch with Errors holding a 409 Conflict error, with a source pointer such as "/data/type".
+// Since these checks are made before SetType is called, SetType implementations need not repeat them.
+//
+// Along with ForCreate, the options WithClientIDPolicy, WithClientIDValidator and WithClientIDLookup control
+// whether and which client-generated IDs are accepted, see ClientIDPolicy.
+//
+// The option WithLocalIDs resolves the local identifiers ("lid") of the resource and its relationships' data
+// to the IDs the server assigned to resources created earlier within the same request.
+func FromResource(adapter interface{}, r *Resource, full bool, opts ...FromResourceOption) error {
 	v, ok := adapter.(identityWriteAdapter)
 	if !ok {
 		return errResourceIdentity
 	}
 
+	o := newFromResourceOptions(opts)
+	if o.localIDs != nil {
+		resolved, err := o.resolveLocalIDs(r)
+		if err != nil {
+			return err
+		}
+		r = resolved
+	}
+	if err := o.checkIdentity(r); err != nil {
+		return err
+	}
+	if o.intent == intentCreate {
+		if err := o.checkClientID(r); err != nil {
+			return err
+		}
+	}
+	if full && o.intent != intentUnknown {
+		if v, ok := adapter.(attributeAccessAdapter); ok {
+			if err := checkAttributeAccess(v.GetAttributeAccess(), r.Attributes, o.intent); err != nil {
+				return err
+			}
+		}
+	}
+
 	// skip setting ID for edge case when creating new resource
 	if len(r.ID) > 0 {
 		if err := v.SetID(r.ID); err != nil {
@@ -213,6 +268,10 @@ type attributesWriteAdapter interface {
 	SetAttributes(map[string]interface{}) error
 }
 
+type attributeAccessAdapter interface {
+	GetAttributeAccess() map[string]AttributeAccess
+}
+
 // relationships adapters
 
 type relationshipsReadAdapter interface {
@@ -223,6 +282,18 @@ type relationshipsWriteAdapter interface {
 	SetRelationships(Relationships) error
 }
 
+type relationshipReplaceAdapter interface {
+	ReplaceRelationship(string, *Relationship) error
+}
+
+type relationshipAddAdapter interface {
+	AddToRelationship(string, []ResourceIdentifier) error
+}
+
+type relationshipRemoveAdapter interface {
+	RemoveFromRelationship(string, []ResourceIdentifier) error
+}
+
 // links adapters
 
 type linksReadAdapter interface {
@@ -0,0 +1,269 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/smotes/jsonapi"
+	"github.com/smotes/jsonapi/server"
+)
+
+type testArticle struct {
+	ID       string
+	Title    string
+	AuthorID string
+	TagIDs   []string
+}
+
+func (a *testArticle) GetID() (string, error)   { return a.ID, nil }
+func (a *testArticle) GetType() (string, error) { return "articles", nil }
+func (a *testArticle) SetID(id string) error    { a.ID = id; return nil }
+func (a *testArticle) SetType(string) error     { return nil }
+
+func (a *testArticle) GetAttributes() (map[string]interface{}, error) {
+	return map[string]interface{}{"title": a.Title}, nil
+}
+
+func (a *testArticle) SetAttributes(as map[string]interface{}) error {
+	if v, ok := jsonapi.Attributes(as).String("title"); ok {
+		a.Title = v
+	}
+	return nil
+}
+
+func (a *testArticle) GetRelationships() (jsonapi.Relationships, error) {
+	author := &jsonapi.Relationship{}
+	var ri *jsonapi.ResourceIdentifier
+	if len(a.AuthorID) > 0 {
+		ri = &jsonapi.ResourceIdentifier{Type: "people", ID: a.AuthorID}
+	}
+	if err := author.SetToOne(ri); err != nil {
+		return nil, err
+	}
+	tags := &jsonapi.Relationship{}
+	ris := make([]jsonapi.ResourceIdentifier, len(a.TagIDs))
+	for i, id := range a.TagIDs {
+		ris[i] = jsonapi.ResourceIdentifier{Type: "tags", ID: id}
+	}
+	if err := tags.SetToMany(ris); err != nil {
+		return nil, err
+	}
+	return jsonapi.Relationships{"author": author, "tags": tags}, nil
+}
+
+func (a *testArticle) AddToRelationship(name string, ris []jsonapi.ResourceIdentifier) error {
+	if err := checkToMany(name); err != nil {
+		return err
+	}
+	for _, ri := range ris {
+		if !containsString(a.TagIDs, ri.ID) {
+			a.TagIDs = append(a.TagIDs, ri.ID)
+		}
+	}
+	return nil
+}
+
+func (a *testArticle) RemoveFromRelationship(name string, ris []jsonapi.ResourceIdentifier) error {
+	if err := checkToMany(name); err != nil {
+		return err
+	}
+	var ids []string
+	for _, id := range a.TagIDs {
+		if !containsIdentifier(ris, id) {
+			ids = append(ids, id)
+		}
+	}
+	a.TagIDs = ids
+	return nil
+}
+
+// checkToMany returns a 404 Not Found error for any to-many relationship of articles other than tags.
+func checkToMany(name string) error {
+	if name == "tags" {
+		return nil
+	}
+	return &jsonapi.Error{
+		Status: fmt.Sprint(http.StatusNotFound),
+		Title:  http.StatusText(http.StatusNotFound),
+		Detail: fmt.Sprintf("relationship %q does not exist", name),
+	}
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsIdentifier(ris []jsonapi.ResourceIdentifier, id string) bool {
+	for _, ri := range ris {
+		if ri.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *testArticle) SetRelationships(rs jsonapi.Relationships) error {
+	if rel, ok := rs.Get("author"); ok {
+		ri, err := rel.ToOne()
+		if err != nil {
+			return err
+		}
+		a.AuthorID = ""
+		if ri != nil {
+			a.AuthorID = ri.ID
+		}
+	}
+	return nil
+}
+
+type testPerson struct {
+	ID   string
+	Name string
+}
+
+func (p *testPerson) GetID() (string, error)   { return p.ID, nil }
+func (p *testPerson) GetType() (string, error) { return "people", nil }
+func (p *testPerson) SetID(id string) error    { p.ID = id; return nil }
+func (p *testPerson) SetType(string) error     { return nil }
+
+func (p *testPerson) GetAttributes() (map[string]interface{}, error) {
+	return map[string]interface{}{"name": p.Name}, nil
+}
+
+func (p *testPerson) GetRelationships() (jsonapi.Relationships, error) {
+	return jsonapi.Relationships{"articles": &jsonapi.Relationship{}}, nil
+}
+
+// testRepository is a minimal Repository storing articles and people, which relate to each other by the
+// author relationship of articles.
+type testRepository struct {
+	typ   string
+	mu    *sync.Mutex
+	store map[string]map[string]interface{}
+	next  int
+}
+
+func newTestRepositories() (articles, people *testRepository) {
+	mu := &sync.Mutex{}
+	store := map[string]map[string]interface{}{
+		"articles": {
+			"1": &testArticle{ID: "1", Title: "foo", AuthorID: "9", TagIDs: []string{"a"}},
+			"2": &testArticle{ID: "2", Title: "bar"},
+		},
+		"people": {
+			"9": &testPerson{ID: "9", Name: "baz"},
+		},
+	}
+	return &testRepository{typ: "articles", mu: mu, store: store, next: 2},
+		&testRepository{typ: "people", mu: mu, store: store, next: 9}
+}
+
+func (repo *testRepository) New() interface{} {
+	if repo.typ == "articles" {
+		return &testArticle{}
+	}
+	return &testPerson{}
+}
+
+func (repo *testRepository) FindAll(ctx context.Context, q *server.Query) ([]interface{}, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	ids := make([]string, 0, len(repo.store[repo.typ]))
+	for id := range repo.store[repo.typ] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	vs := make([]interface{}, len(ids))
+	for i, id := range ids {
+		vs[i] = repo.store[repo.typ][id]
+	}
+	return vs, nil
+}
+
+func (repo *testRepository) FindOne(ctx context.Context, id string) (interface{}, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	v, ok := repo.store[repo.typ][id]
+	if !ok {
+		return nil, server.ErrNotFound
+	}
+	return v, nil
+}
+
+func (repo *testRepository) FindRelated(ctx context.Context, id, name string) ([]interface{}, error) {
+	v, err := repo.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	switch v := v.(type) {
+	case *testArticle:
+		if p, ok := repo.store["people"][v.AuthorID]; ok {
+			return []interface{}{p}, nil
+		}
+	case *testPerson:
+		var vs []interface{}
+		for _, a := range repo.store["articles"] {
+			if a.(*testArticle).AuthorID == v.ID {
+				vs = append(vs, a)
+			}
+		}
+		return vs, nil
+	}
+	return nil, nil
+}
+
+func (repo *testRepository) Create(ctx context.Context, v interface{}) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	r, _ := jsonapi.ToResource(v, false)
+	if len(r.ID) == 0 {
+		repo.next++
+		r.ID = strconv.Itoa(repo.next)
+		v.(interface{ SetID(string) error }).SetID(r.ID)
+	}
+	if _, ok := repo.store[repo.typ][r.ID]; ok {
+		return server.ErrConflict
+	}
+	repo.store[repo.typ][r.ID] = v
+	return nil
+}
+
+func (repo *testRepository) Update(ctx context.Context, v interface{}) error {
+	return nil
+}
+
+func (repo *testRepository) Delete(ctx context.Context, id string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.store[repo.typ][id]; !ok {
+		return server.ErrNotFound
+	}
+	delete(repo.store[repo.typ], id)
+	return nil
+}
+
+func newTestHandler() *server.Handler {
+	articles, people := newTestRepositories()
+	h := &server.Handler{BasePath: "/api"}
+	h.Register("articles", articles, jsonapi.WithClientIDPolicy(jsonapi.ClientIDForbidden))
+	h.Register("people", people)
+	return h
+}
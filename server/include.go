@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/smotes/jsonapi"
+)
+
+// includeConcurrency is the maximum number of batches of included resources loaded at the same time for a
+// single request.
+const includeConcurrency = 4
+
+// includer returns the resolver for the resources included in the documents of the request, which loads them
+// from the Repository of each type and caches them until the request is served.
+func (req *request) includer() *jsonapi.IncludeResolver {
+	if req.inc == nil {
+		req.inc = &jsonapi.IncludeResolver{
+			Loader:      jsonapi.ResourceLoaderFunc(req.h.loadResources),
+			Concurrency: includeConcurrency,
+			Graph:       req.h.Relationships,
+		}
+	}
+	return req.inc
+}
+
+// loadResources loads the resources of type typ with the given IDs, using the FindMany method of the repository
+// if it implements it, see Repository, and FindOne for each ID otherwise. Types which are not registered and
+// resources which do not exist are skipped.
+func (h *Handler) loadResources(ctx context.Context, typ string, ids []string) ([]jsonapi.Resource, error) {
+	repo, ok := h.repository(typ)
+	if !ok {
+		return nil, nil
+	}
+
+	var vs []interface{}
+	if batch, ok := repo.(batchRepository); ok {
+		found, err := batch.FindMany(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		vs = found
+	} else {
+		for _, id := range ids {
+			v, err := repo.FindOne(ctx, id)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, v)
+		}
+	}
+
+	rs := make([]jsonapi.Resource, len(vs))
+	for i, v := range vs {
+		r, err := jsonapi.ToResource(v, true)
+		if err != nil {
+			return nil, err
+		}
+		rs[i] = *r
+	}
+	return rs, nil
+}
@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/smotes/jsonapi"
+)
+
+// Entry wraps a copy of a stored resource, implementing the adapter interfaces used by jsonapi.ToResource,
+// jsonapi.FromResource and jsonapi.UpdateRelationship, so the resource can be read and updated by a handler.
+// Changes to an Entry only take effect once it is passed to the Update method of the repository.
+//
+// Updates follow the semantics of the JSON API specification: attributes and relationships missing from an
+// update are left untouched, members already part of a to-many relationship are not added again, and members
+// not part of it are ignored when removed.
+type Entry struct {
+	r jsonapi.Resource
+}
+
+// Resource returns a copy of the resource held by the entry.
+func (e *Entry) Resource() jsonapi.Resource {
+	return clone(e.r)
+}
+
+// GetID returns the ID of the resource.
+func (e *Entry) GetID() (string, error) {
+	return e.r.ID, nil
+}
+
+// GetType returns the type of the resource.
+func (e *Entry) GetType() (string, error) {
+	return e.r.Type, nil
+}
+
+// SetID sets the ID of the resource.
+func (e *Entry) SetID(id string) error {
+	e.r.ID = id
+	return nil
+}
+
+// SetType sets the type of the resource.
+func (e *Entry) SetType(typ string) error {
+	e.r.Type = typ
+	return nil
+}
+
+// GetAttributes returns the attributes of the resource.
+func (e *Entry) GetAttributes() (map[string]interface{}, error) {
+	return e.r.Attributes, nil
+}
+
+// SetAttributes sets the given attributes of the resource, leaving any others untouched.
+func (e *Entry) SetAttributes(as map[string]interface{}) error {
+	if len(as) > 0 && e.r.Attributes == nil {
+		e.r.Attributes = make(jsonapi.Attributes, len(as))
+	}
+	for k, v := range as {
+		e.r.Attributes[k] = v
+	}
+	return nil
+}
+
+// GetRelationships returns the relationships of the resource.
+func (e *Entry) GetRelationships() (jsonapi.Relationships, error) {
+	return e.r.Relationships, nil
+}
+
+// SetRelationships replaces the data of the given relationships of the resource, leaving any others untouched.
+func (e *Entry) SetRelationships(rs jsonapi.Relationships) error {
+	for name, rel := range rs {
+		if rel == nil || rel.Data == nil {
+			continue
+		}
+		if err := e.ReplaceRelationship(name, rel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLinks returns the links of the resource.
+func (e *Entry) GetLinks() (jsonapi.Links, error) {
+	return e.r.Links, nil
+}
+
+// GetMeta returns the meta object of the resource.
+func (e *Entry) GetMeta() (map[string]interface{}, error) {
+	return e.r.Meta, nil
+}
+
+// ReplaceRelationship replaces the data of the relationship name, keeping its links and meta.
+func (e *Entry) ReplaceRelationship(name string, rel *jsonapi.Relationship) error {
+	if e.r.Relationships == nil {
+		e.r.Relationships = make(jsonapi.Relationships)
+	}
+	current, ok := e.r.Relationships.Get(name)
+	if !ok || current == nil {
+		current = &jsonapi.Relationship{}
+		e.r.Relationships.Add(name, current)
+	}
+	current.Data = append([]byte(nil), rel.Data...)
+	return nil
+}
+
+// AddToRelationship adds the resource identifiers to the to-many relationship name, unless already present.
+func (e *Entry) AddToRelationship(name string, ris []jsonapi.ResourceIdentifier) error {
+	current, err := e.toMany(name)
+	if err != nil {
+		return err
+	}
+	for _, ri := range ris {
+		if indexOf(current, ri) < 0 {
+			current = append(current, jsonapi.ResourceIdentifier{Type: ri.Type, ID: ri.ID})
+		}
+	}
+	return e.setToMany(name, current)
+}
+
+// RemoveFromRelationship removes the resource identifiers from the to-many relationship name, if present.
+func (e *Entry) RemoveFromRelationship(name string, ris []jsonapi.ResourceIdentifier) error {
+	current, err := e.toMany(name)
+	if err != nil {
+		return err
+	}
+	kept := []jsonapi.ResourceIdentifier{}
+	for _, ri := range current {
+		if indexOf(ris, ri) < 0 {
+			kept = append(kept, ri)
+		}
+	}
+	return e.setToMany(name, kept)
+}
+
+// toMany returns the resource identifiers of the to-many relationship name, or a 403 Forbidden error if the
+// relationship is to-one.
+func (e *Entry) toMany(name string) ([]jsonapi.ResourceIdentifier, error) {
+	rel, ok := e.r.Relationships.Get(name)
+	if !ok || rel == nil || rel.Data == nil {
+		return nil, nil
+	}
+	if !rel.IsToMany() {
+		return nil, &jsonapi.Error{
+			Status: fmt.Sprint(http.StatusForbidden),
+			Title:  "Forbidden",
+			Detail: fmt.Sprintf("relationship %q is not a to-many relationship", name),
+		}
+	}
+	return rel.ToMany()
+}
+
+func (e *Entry) setToMany(name string, ris []jsonapi.ResourceIdentifier) error {
+	rel := &jsonapi.Relationship{}
+	if err := rel.SetToMany(ris); err != nil {
+		return err
+	}
+	return e.ReplaceRelationship(name, rel)
+}
+
+func indexOf(ris []jsonapi.ResourceIdentifier, ri jsonapi.ResourceIdentifier) int {
+	for i, other := range ris {
+		if other.Type == ri.Type && other.ID == ri.ID {
+			return i
+		}
+	}
+	return -1
+}
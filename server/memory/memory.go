@@ -0,0 +1,495 @@
+// Package memory implements an in-memory store of JSON API resources, serving as a server.Repository for each
+// resource type, e.g. for prototyping new resource types or for testing handlers without a database.
+//
+// The store holds jsonapi.Resource values keyed by type and ID, and hands out copies of them wrapped in an Entry,
+// which implements all adapter interfaces of the jsonapi package:
+//
+//	store := memory.NewStore()
+//	store.Put(jsonapi.Resource{ID: "1", Type: "articles", Attributes: jsonapi.Attributes{"title": "foo"}})
+//
+//	h := &server.Handler{}
+//	h.Register("articles", store.Repository("articles"))
+//
+// Queries are supported as follows:
+//
+//   - filter[name]=v1,v2 matches resources whose attribute name, or the ID of whose relationship name, equals any
+//     of the values, with "id" matching the resource's ID;
+//   - sort=-name,... orders resources by attributes, or "id", comparing numbers, strings and booleans;
+//   - page[number] and page[size], or page[offset] and page[limit], select a page of resources.
+package memory // import "github.com/smotes/jsonapi/server/memory"
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/smotes/jsonapi"
+	"github.com/smotes/jsonapi/server"
+)
+
+// Store is an in-memory store of resources keyed by type and ID. It is safe for concurrent use.
+type Store struct {
+	mu        sync.RWMutex
+	resources map[string]map[string]jsonapi.Resource
+	lastID    map[string]int
+}
+
+// NewStore returns a new, empty store.
+func NewStore() *Store {
+	return &Store{
+		resources: make(map[string]map[string]jsonapi.Resource),
+		lastID:    make(map[string]int),
+	}
+}
+
+// Put stores a copy of the resource r, replacing any resource with the same type and ID.
+// If r has no ID, it is assigned the next sequential ID of its type, which Put returns.
+func (s *Store) Put(r jsonapi.Resource) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(r.ID) == 0 {
+		r.ID = s.nextID(r.Type)
+	}
+	s.put(r)
+	return r.ID
+}
+
+// Get returns a copy of the resource with the given type and ID, and an existence check.
+func (s *Store) Get(typ, id string) (jsonapi.Resource, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.resources[typ][id]
+	if !ok {
+		return jsonapi.Resource{}, false
+	}
+	return clone(r), true
+}
+
+// Delete removes the resource with the given type and ID, and returns whether it existed.
+func (s *Store) Delete(typ, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.resources[typ][id]; !ok {
+		return false
+	}
+	delete(s.resources[typ], id)
+	return true
+}
+
+// Repository returns the repository serving the resources of type typ.
+func (s *Store) Repository(typ string) server.Repository {
+	return &repository{s: s, typ: typ}
+}
+
+func (s *Store) put(r jsonapi.Resource) {
+	if s.resources[r.Type] == nil {
+		s.resources[r.Type] = make(map[string]jsonapi.Resource)
+	}
+	s.resources[r.Type][r.ID] = clone(r)
+
+	// keep sequential IDs clear of IDs assigned otherwise
+	if n, err := strconv.Atoi(r.ID); err == nil && n > s.lastID[r.Type] {
+		s.lastID[r.Type] = n
+	}
+}
+
+func (s *Store) nextID(typ string) string {
+	for {
+		s.lastID[typ]++
+		id := strconv.Itoa(s.lastID[typ])
+		if _, ok := s.resources[typ][id]; !ok {
+			return id
+		}
+	}
+}
+
+// repository implements server.Repository for a single type of the store.
+type repository struct {
+	s   *Store
+	typ string
+}
+
+func (repo *repository) New() interface{} {
+	return &Entry{r: jsonapi.Resource{Type: repo.typ}}
+}
+
+func (repo *repository) FindAll(ctx context.Context, q *server.Query) ([]interface{}, error) {
+	repo.s.mu.RLock()
+	rs := make([]jsonapi.Resource, 0, len(repo.s.resources[repo.typ]))
+	for _, r := range repo.s.resources[repo.typ] {
+		if matches(&r, q.Filter) {
+			rs = append(rs, clone(r))
+		}
+	}
+	repo.s.mu.RUnlock()
+
+	sortResources(rs, q.Sort)
+	rs, err := paginate(rs, q.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	vs := make([]interface{}, len(rs))
+	for i := range rs {
+		vs[i] = &Entry{r: rs[i]}
+	}
+	return vs, nil
+}
+
+func (repo *repository) FindOne(ctx context.Context, id string) (interface{}, error) {
+	r, ok := repo.s.Get(repo.typ, id)
+	if !ok {
+		return nil, server.ErrNotFound
+	}
+	return &Entry{r: r}, nil
+}
+
+// FindMany returns the entries with the given IDs, so included resources are loaded in batches.
+func (repo *repository) FindMany(ctx context.Context, ids []string) ([]interface{}, error) {
+	repo.s.mu.RLock()
+	defer repo.s.mu.RUnlock()
+
+	var vs []interface{}
+	for _, id := range ids {
+		if r, ok := repo.s.resources[repo.typ][id]; ok {
+			vs = append(vs, &Entry{r: clone(r)})
+		}
+	}
+	return vs, nil
+}
+
+func (repo *repository) FindRelated(ctx context.Context, id, name string) ([]interface{}, error) {
+	r, ok := repo.s.Get(repo.typ, id)
+	if !ok {
+		return nil, server.ErrNotFound
+	}
+	rel, ok := r.Relationships.Get(name)
+	if !ok || rel == nil {
+		return nil, nil
+	}
+
+	ris, err := identifiers(rel)
+	if err != nil {
+		return nil, err
+	}
+
+	// identifiers of resources which do not exist (anymore) are skipped
+	var vs []interface{}
+	for _, ri := range ris {
+		if related, ok := repo.s.Get(ri.Type, ri.ID); ok {
+			vs = append(vs, &Entry{r: related})
+		}
+	}
+	return vs, nil
+}
+
+func (repo *repository) Create(ctx context.Context, v interface{}) error {
+	e, err := repo.entry(v)
+	if err != nil {
+		return err
+	}
+
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	if len(e.r.ID) == 0 {
+		e.r.ID = repo.s.nextID(repo.typ)
+	} else if _, ok := repo.s.resources[repo.typ][e.r.ID]; ok {
+		return server.ErrConflict
+	}
+	e.r.Lid = ""
+	repo.s.put(e.r)
+	return nil
+}
+
+func (repo *repository) Update(ctx context.Context, v interface{}) error {
+	e, err := repo.entry(v)
+	if err != nil {
+		return err
+	}
+
+	repo.s.mu.Lock()
+	defer repo.s.mu.Unlock()
+
+	if _, ok := repo.s.resources[repo.typ][e.r.ID]; !ok {
+		return server.ErrNotFound
+	}
+	repo.s.put(e.r)
+	return nil
+}
+
+func (repo *repository) Delete(ctx context.Context, id string) error {
+	if !repo.s.Delete(repo.typ, id) {
+		return server.ErrNotFound
+	}
+	return nil
+}
+
+func (repo *repository) entry(v interface{}) (*Entry, error) {
+	e, ok := v.(*Entry)
+	if !ok || e.r.Type != repo.typ {
+		return nil, fmt.Errorf("%s: value of type %T is not an entry of type %q", packageName, v, repo.typ)
+	}
+	return e, nil
+}
+
+// matches returns true if the resource r matches all filters.
+func matches(r *jsonapi.Resource, filters map[string]string) bool {
+	for name, values := range filters {
+		var actual []string
+		switch {
+		case name == "id":
+			actual = []string{r.ID}
+		case r.HasAttribute(name):
+			v, _ := r.Attributes.Get(name)
+			actual = []string{fmt.Sprint(v)}
+		case r.HasRelationship(name):
+			rel, _ := r.Relationships.Get(name)
+			ris, _ := identifiers(rel)
+			for _, ri := range ris {
+				actual = append(actual, ri.ID)
+			}
+		}
+		if !containsAny(actual, strings.Split(values, ",")) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortResources sorts rs by the sort fields, and by ID otherwise so the order is stable.
+func sortResources(rs []jsonapi.Resource, fields []server.SortField) {
+	sort.SliceStable(rs, func(i, j int) bool {
+		for _, f := range fields {
+			c := compare(sortValue(&rs[i], f.Field), sortValue(&rs[j], f.Field))
+			if f.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return compare(rs[i].ID, rs[j].ID) < 0
+	})
+}
+
+func sortValue(r *jsonapi.Resource, field string) interface{} {
+	if field == "id" {
+		return r.ID
+	}
+	v, _ := r.Attributes.Get(field)
+	return v
+}
+
+// compare compares two attribute values, ordering null before booleans, numbers and strings, in that order.
+// Strings holding integers, such as sequential IDs, are compared as numbers.
+func compare(a, b interface{}) int {
+	ra, rb := rank(a), rank(b)
+	if ra != rb {
+		return ra - rb
+	}
+
+	switch a := a.(type) {
+	case bool:
+		return compareBool(a, b.(bool))
+	case string:
+		b := b.(string)
+		if na, err := strconv.ParseFloat(a, 64); err == nil {
+			if nb, err := strconv.ParseFloat(b, 64); err == nil {
+				return compareFloat(na, nb)
+			}
+		}
+		return strings.Compare(a, b)
+	}
+
+	if na, ok := number(a); ok {
+		nb, _ := number(b)
+		return compareFloat(na, nb)
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func rank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case string:
+		return 3
+	}
+	if _, ok := number(v); ok {
+		return 2
+	}
+	return 4
+}
+
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case interface{ Float64() (float64, error) }:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	}
+	return 1
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// paginate returns the page of rs selected by the page query parameters.
+func paginate(rs []jsonapi.Resource, page map[string]string) ([]jsonapi.Resource, error) {
+	if len(page) == 0 {
+		return rs, nil
+	}
+
+	params := make(map[string]int, len(page))
+	for key, v := range page {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, pageError(key, "must be a non-negative integer")
+		}
+		params[key] = n
+	}
+
+	var offset, limit int
+	switch {
+	case hasKey(page, "offset") || hasKey(page, "limit"):
+		offset = params["offset"]
+		limit = len(rs)
+		if l, ok := params["limit"]; ok {
+			limit = l
+		}
+	case hasKey(page, "number") || hasKey(page, "size"):
+		limit = len(rs)
+		if size, ok := params["size"]; ok {
+			if size == 0 {
+				return nil, pageError("size", "must be a positive integer")
+			}
+			limit = size
+		}
+		number := 1
+		if n, ok := params["number"]; ok {
+			if n == 0 {
+				return nil, pageError("number", "must be a positive integer")
+			}
+			number = n
+		}
+		offset = (number - 1) * limit
+	default:
+		return rs, nil
+	}
+
+	if offset >= len(rs) {
+		return []jsonapi.Resource{}, nil
+	}
+	end := offset + limit
+	if end > len(rs) {
+		end = len(rs)
+	}
+	return rs[offset:end], nil
+}
+
+// helpers
+
+// identifiers returns the resource identifiers of the relationship's data, whether to-one or to-many.
+func identifiers(rel *jsonapi.Relationship) ([]jsonapi.ResourceIdentifier, error) {
+	if rel.IsToMany() {
+		return rel.ToMany()
+	}
+	ri, err := rel.ToOne()
+	if err != nil || ri == nil {
+		return nil, err
+	}
+	return []jsonapi.ResourceIdentifier{*ri}, nil
+}
+
+// clone returns a copy of r which shares no maps with it, so that either can be modified independently.
+func clone(r jsonapi.Resource) jsonapi.Resource {
+	c := r
+	if r.Attributes != nil {
+		c.Attributes = make(jsonapi.Attributes, len(r.Attributes))
+		for k, v := range r.Attributes {
+			c.Attributes[k] = v
+		}
+	}
+	if r.Relationships != nil {
+		c.Relationships = make(jsonapi.Relationships, len(r.Relationships))
+		for k, rel := range r.Relationships {
+			if rel != nil {
+				copied := *rel
+				rel = &copied
+			}
+			c.Relationships[k] = rel
+		}
+	}
+	if r.Links != nil {
+		c.Links = make(jsonapi.Links, len(r.Links))
+		for k, v := range r.Links {
+			c.Links[k] = v
+		}
+	}
+	if r.Meta != nil {
+		c.Meta = make(jsonapi.Meta, len(r.Meta))
+		for k, v := range r.Meta {
+			c.Meta[k] = v
+		}
+	}
+	return c
+}
+
+func containsAny(ss, values []string) bool {
+	for _, s := range ss {
+		for _, v := range values {
+			if s == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasKey(m map[string]string, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+func pageError(param, detail string) *jsonapi.Error {
+	return &jsonapi.Error{
+		Status: fmt.Sprint(http.StatusBadRequest),
+		Title:  "Invalid page",
+		Detail: fmt.Sprintf("page[%s] %s", param, detail),
+		Source: &jsonapi.ErrorSource{Parameter: "page[" + param + "]"},
+	}
+}
+
+const packageName string = "github.com/smotes/jsonapi/server/memory"
@@ -0,0 +1,225 @@
+package memory_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+	"github.com/smotes/jsonapi/server"
+	"github.com/smotes/jsonapi/server/memory"
+)
+
+func newTestStore() *memory.Store {
+	store := memory.NewStore()
+	for _, r := range []jsonapi.Resource{
+		{Type: "people", Attributes: jsonapi.Attributes{"name": "foo"}},
+		{Type: "people", Attributes: jsonapi.Attributes{"name": "bar"}},
+		{Type: "articles", Attributes: jsonapi.Attributes{"title": "c", "views": 10.0}},
+		{Type: "articles", Attributes: jsonapi.Attributes{"title": "a", "views": 30.0}},
+		{Type: "articles", Attributes: jsonapi.Attributes{"title": "b", "views": 20.0}},
+	} {
+		store.Put(r)
+	}
+
+	author := &jsonapi.Relationship{}
+	author.SetToOne(&jsonapi.ResourceIdentifier{Type: "people", ID: "1"})
+	tags := &jsonapi.Relationship{}
+	tags.SetToMany(nil)
+	for _, id := range []string{"1", "2"} {
+		r, _ := store.Get("articles", id)
+		r.Relationships = jsonapi.Relationships{"author": author, "tags": tags}
+		store.Put(r)
+	}
+	return store
+}
+
+func findAll(t *testing.T, store *memory.Store, query string) ([]string, error) {
+	values, _ := url.ParseQuery(query)
+	q, err := server.ParseQuery(values)
+	if err != nil {
+		t.Fatalf("unexpected error from ParseQuery: %+v", err)
+	}
+
+	vs, err := store.Repository("articles").FindAll(context.Background(), q)
+	ids := make([]string, len(vs))
+	for i, v := range vs {
+		ids[i], _ = v.(*memory.Entry).GetID()
+	}
+	return ids, err
+}
+
+func TestRepository_FindAll(t *testing.T) {
+	store := newTestStore()
+
+	tests := []struct {
+		query string
+		ids   []string
+	}{
+		{"", []string{"1", "2", "3"}},
+		{"sort=title", []string{"2", "3", "1"}},
+		{"sort=-views", []string{"2", "3", "1"}},
+		{"sort=-id", []string{"3", "2", "1"}},
+		{"filter[title]=a,c", []string{"1", "2"}},
+		{"filter[views]=20", []string{"3"}},
+		{"filter[author]=1", []string{"1", "2"}},
+		{"filter[author]=1&filter[id]=2", []string{"2"}},
+		{"filter[unknown]=1", []string{}},
+		{"page[size]=2", []string{"1", "2"}},
+		{"page[size]=2&page[number]=2", []string{"3"}},
+		{"page[size]=2&page[number]=3", []string{}},
+		{"page[offset]=1&page[limit]=1&sort=title", []string{"3"}},
+	}
+
+	for _, test := range tests {
+		ids, err := findAll(t, store, test.query)
+		if err != nil {
+			t.Errorf("%s: unexpected error from FindAll: %+v", test.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(ids, test.ids) {
+			t.Errorf("%s: unexpected resources, expected: %v, actual: %v", test.query, test.ids, ids)
+		}
+	}
+
+	for _, query := range []string{"page[size]=0", "page[number]=x"} {
+		_, err := findAll(t, store, query)
+		if e, ok := err.(*jsonapi.Error); !ok || e.Status != "400" {
+			t.Errorf("%s: expected 400 error from FindAll, actual: %+v", query, err)
+		}
+	}
+}
+
+func TestStore_CopiesResources(t *testing.T) {
+	store := newTestStore()
+	repo := store.Repository("articles")
+
+	v, err := repo.FindOne(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("unexpected error from FindOne: %+v", err)
+	}
+	e := v.(*memory.Entry)
+	e.SetAttributes(map[string]interface{}{"title": "changed"})
+
+	if r, _ := store.Get("articles", "1"); r.Attributes["title"] != "c" {
+		t.Errorf("expected changes to entry not to affect the store before Update, actual: %v", r.Attributes)
+	}
+	if err := repo.Update(context.Background(), e); err != nil {
+		t.Fatalf("unexpected error from Update: %+v", err)
+	}
+	if r, _ := store.Get("articles", "1"); r.Attributes["title"] != "changed" || r.Attributes["views"] != 10.0 {
+		t.Errorf("expected Update to store the changed attribute only, actual: %v", r.Attributes)
+	}
+}
+
+func TestEntry_Relationships(t *testing.T) {
+	store := newTestStore()
+	v, _ := store.Repository("articles").FindOne(context.Background(), "1")
+	e := v.(*memory.Entry)
+
+	ris := []jsonapi.ResourceIdentifier{{Type: "tags", ID: "x"}, {Type: "tags", ID: "y"}}
+	e.AddToRelationship("tags", ris)
+	e.AddToRelationship("tags", ris[:1])
+	e.RemoveFromRelationship("tags", []jsonapi.ResourceIdentifier{{Type: "tags", ID: "y"}, {Type: "tags", ID: "z"}})
+
+	r := e.Resource()
+	if rel, _ := r.Relationships.Get("tags"); string(rel.Data) != `[{"type":"tags","id":"x"}]` {
+		t.Errorf("unexpected to-many relationship data: %s", rel.Data)
+	}
+	if err := e.AddToRelationship("author", ris); err == nil {
+		t.Error("expected error when adding to a to-one relationship")
+	}
+}
+
+func TestStore_Handler(t *testing.T) {
+	store := newTestStore()
+	h := &server.Handler{Relationships: &jsonapi.RelationshipGraph{}}
+	h.Register("articles", store.Repository("articles"))
+	h.Register("people", store.Repository("people"))
+	h.Relationships.Add("articles", "author", "people")
+	h.Relationships.Add("articles", "tags", "tags")
+	h.Relationships.AddType("people")
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		if len(body) > 0 {
+			r.Header.Set("Content-Type", jsonapi.MediaType)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve(http.MethodPost, "/articles", `{
+		"data": {
+			"type": "articles",
+			"attributes": {"title": "d"},
+			"relationships": {"author": {"data": {"type": "people", "id": "2"}}}
+		}
+	}`)
+	if w.Code != http.StatusCreated || w.Header().Get("Location") != "/articles/4" {
+		t.Fatalf("unexpected response when creating resource: %d %s", w.Code, w.Body)
+	}
+
+	w = serve(http.MethodPost, "/articles/1/relationships/tags", `{"data": [{"type": "tags", "id": "x"}]}`)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("unexpected response when adding to relationship: %d %s", w.Code, w.Body)
+	}
+
+	// the created article has no tags relationship yet, which is declared though
+	w = serve(http.MethodPost, "/articles/4/relationships/tags", `{"data": [{"type": "tags", "id": "y"}]}`)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("unexpected response when adding to missing relationship: %d %s", w.Code, w.Body)
+	}
+	if r, _ := store.Get("articles", "4"); !r.HasRelationship("tags") {
+		t.Errorf("expected missing relationship to be added: %+v", r.Relationships)
+	}
+
+	w = serve(http.MethodGet, "/articles?include=author&sort=-title&fields[articles]=title&page[size]=2", "")
+	doc := jsonapi.Document{}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("unexpected response when listing resources: %d %s", w.Code, w.Body)
+	}
+	rs := []jsonapi.Resource{}
+	json.Unmarshal(doc.Data, &rs)
+	if len(rs) != 2 || rs[0].ID != "4" || rs[1].ID != "1" || rs[0].Relationships != nil {
+		t.Errorf("unexpected primary data: %s", doc.Data)
+	}
+	if len(doc.Included) != 2 {
+		t.Errorf("expected the authors of both articles to be included, actual: %+v", doc.Included)
+	}
+
+	w = serve(http.MethodDelete, "/people/2", "")
+	w = serve(http.MethodGet, "/articles/4/author", "")
+	if doc := (jsonapi.Document{}); json.Unmarshal(w.Body.Bytes(), &doc) != nil || string(doc.Data) != "null" {
+		t.Errorf("expected related resource which no longer exists to be skipped: %s", w.Body)
+	}
+}
+
+func TestStore_Handler_Numbers(t *testing.T) {
+	store := memory.NewStore()
+	h := &server.Handler{}
+	h.Register("articles", store.Repository("articles"))
+
+	r := httptest.NewRequest(http.MethodPost, "/articles",
+		strings.NewReader(`{"data": {"type": "articles", "attributes": {"views": 9007199254740993}}}`))
+	r.Header.Set("Content-Type", jsonapi.MediaType)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("unexpected response when creating resource: %d %s", w.Code, w.Body)
+	}
+	if !strings.Contains(w.Body.String(), `"views":9007199254740993`) {
+		t.Errorf("expected integer attribute to keep its precision, actual: %s", w.Body)
+	}
+
+	created, _ := store.Get("articles", "1")
+	if v := created.Attributes["views"]; v != json.Number("9007199254740993") {
+		t.Errorf("expected integer attribute to be stored as json.Number, actual: %#v", v)
+	}
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/smotes/jsonapi"
+)
+
+// Query holds the query parameters of a request, as defined by the JSON API specification.
+//
+// http://jsonapi.org/format/#query-parameters
+type Query struct {
+	// Include holds the relationship paths of the "include" parameter, e.g. "comments.author".
+	Include []string
+
+	// Fields holds the sparse fieldsets of the "fields" parameter family by type.
+	Fields map[string][]string
+
+	// Sort holds the sort fields of the "sort" parameter, in order.
+	Sort []SortField
+
+	// Page holds the members of the "page" parameter family, e.g. "number" and "size".
+	Page map[string]string
+
+	// Filter holds the members of the "filter" parameter family, e.g. "author" for "filter[author]".
+	Filter map[string]string
+
+	// Params holds any implementation-specific query parameters.
+	Params url.Values
+}
+
+// SortField is a field of the "sort" query parameter.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ParseQuery parses the JSON API query parameters from the query values of a request.
+//
+// It returns Errors holding a 400 Bad Request error with the source parameter for each invalid parameter,
+// including any unknown parameter whose name consists of lower case letters only, as the specification reserves
+// these names for its own use.
+func ParseQuery(values url.Values) (*Query, error) {
+	q := &Query{}
+
+	// iterate over the parameters in a stable order, so that any errors are too
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs jsonapi.Errors
+	for _, name := range names {
+		v := strings.Join(values[name], ",")
+		family, member := splitParam(name)
+
+		var err *jsonapi.Error
+		switch {
+		case name == "include":
+			q.Include, err = parseInclude(v)
+		case name == "sort":
+			q.Sort, err = parseSort(v)
+		case family == "fields" && len(member) > 0:
+			if q.Fields == nil {
+				q.Fields = make(map[string][]string)
+			}
+			q.Fields[member] = splitList(v)
+		case family == "page" && len(member) > 0:
+			if q.Page == nil {
+				q.Page = make(map[string]string)
+			}
+			q.Page[member] = v
+		case family == "filter" && len(member) > 0:
+			if q.Filter == nil {
+				q.Filter = make(map[string]string)
+			}
+			q.Filter[member] = v
+		case isReservedParam(family):
+			err = paramError(name, "Invalid query parameter", fmt.Sprintf("query parameter %q is not supported", name))
+		default:
+			if q.Params == nil {
+				q.Params = make(url.Values)
+			}
+			q.Params[name] = values[name]
+		}
+		if err != nil {
+			errs = append(errs, *err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return q, nil
+}
+
+// HasField returns true if the field, i.e. attribute or relationship, of the resource type typ is part of its
+// sparse fieldset, or if there is no sparse fieldset for typ.
+func (q *Query) HasField(typ, field string) bool {
+	fields, ok := q.Fields[typ]
+	if !ok {
+		return true
+	}
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// sparse returns a copy of the resource r holding only the attributes and relationships of its sparse fieldset.
+func (q *Query) sparse(r *jsonapi.Resource) jsonapi.Resource {
+	if _, ok := q.Fields[r.Type]; !ok {
+		return *r
+	}
+
+	temp := *r
+	temp.Attributes, temp.Relationships = nil, nil
+	for key, v := range r.Attributes {
+		if q.HasField(r.Type, key) {
+			if temp.Attributes == nil {
+				temp.Attributes = make(jsonapi.Attributes)
+			}
+			temp.Attributes[key] = v
+		}
+	}
+	for key, rel := range r.Relationships {
+		if q.HasField(r.Type, key) {
+			if temp.Relationships == nil {
+				temp.Relationships = make(jsonapi.Relationships)
+			}
+			temp.Relationships[key] = rel
+		}
+	}
+	return temp
+}
+
+func parseInclude(v string) ([]string, *jsonapi.Error) {
+	paths := splitList(v)
+	for _, path := range paths {
+		for _, name := range strings.Split(path, ".") {
+			if len(name) == 0 {
+				return nil, paramError("include", "Invalid include", fmt.Sprintf("relationship path %q is invalid", path))
+			}
+		}
+	}
+	return paths, nil
+}
+
+func parseSort(v string) ([]SortField, *jsonapi.Error) {
+	var fields []SortField
+	for _, f := range splitList(v) {
+		sf := SortField{Field: strings.TrimPrefix(f, "-"), Desc: strings.HasPrefix(f, "-")}
+		if len(sf.Field) == 0 {
+			return nil, paramError("sort", "Invalid sort", fmt.Sprintf("sort field %q is invalid", f))
+		}
+		fields = append(fields, sf)
+	}
+	return fields, nil
+}
+
+// helpers
+
+// splitParam splits a query parameter name such as "page[size]" into its family and member names.
+func splitParam(name string) (family, member string) {
+	i := strings.IndexByte(name, '[')
+	if i < 0 || !strings.HasSuffix(name, "]") {
+		return name, ""
+	}
+	return name[:i], name[i+1 : len(name)-1]
+}
+
+// splitList splits a comma-separated list, dropping empty elements.
+func splitList(v string) []string {
+	list := []string{}
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); len(s) > 0 {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// isReservedParam returns true if the parameter family name consists of lower case letters only, which makes
+// it reserved by the specification.
+//
+// http://jsonapi.org/format/#query-parameters-custom
+func isReservedParam(family string) bool {
+	for _, c := range family {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return len(family) > 0
+}
+
+func paramError(param, title, detail string) *jsonapi.Error {
+	return &jsonapi.Error{
+		Status: fmt.Sprint(http.StatusBadRequest),
+		Title:  title,
+		Detail: detail,
+		Source: &jsonapi.ErrorSource{Parameter: param},
+	}
+}
@@ -0,0 +1,51 @@
+package server_test
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+	"github.com/smotes/jsonapi/server"
+)
+
+func TestParseQuery(t *testing.T) {
+	values, _ := url.ParseQuery("include=author,comments.author&fields[articles]=title,body&sort=-created,title" +
+		"&page[number]=2&page[size]=10&filter[author]=9&camelCase=1")
+
+	q, err := server.ParseQuery(values)
+	if err != nil {
+		t.Fatalf("unexpected error from ParseQuery: %+v", err)
+	}
+
+	expected := &server.Query{
+		Include: []string{"author", "comments.author"},
+		Fields:  map[string][]string{"articles": {"title", "body"}},
+		Sort:    []server.SortField{{Field: "created", Desc: true}, {Field: "title"}},
+		Page:    map[string]string{"number": "2", "size": "10"},
+		Filter:  map[string]string{"author": "9"},
+		Params:  url.Values{"camelCase": {"1"}},
+	}
+	if !reflect.DeepEqual(q, expected) {
+		t.Errorf("unexpected query, expected: %+v, actual: %+v", expected, q)
+	}
+
+	if !q.HasField("articles", "title") || q.HasField("articles", "author") || !q.HasField("people", "name") {
+		t.Error("unexpected result from Query.HasField")
+	}
+}
+
+func TestParseQuery_WhenInvalid(t *testing.T) {
+	values, _ := url.ParseQuery("include=author..name&sort=-&unknown=1")
+
+	_, err := server.ParseQuery(values)
+	errs, ok := err.(jsonapi.Errors)
+	if !ok || len(errs) != 3 {
+		t.Fatalf("expected ParseQuery to return 3 errors, actual: %+v", err)
+	}
+	for i, param := range []string{"include", "sort", "unknown"} {
+		if errs[i].Status != "400" || errs[i].Source == nil || errs[i].Source.Parameter != param {
+			t.Errorf("unexpected error, expected parameter: %s, actual: %+v", param, errs[i])
+		}
+	}
+}
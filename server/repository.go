@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"fmt"
+)
+
+// Repository stores the resources of a single type on behalf of a Handler.
+//
+// Its values are the custom types implementing the adapter interfaces of the jsonapi package, see
+// jsonapi.ToResource and jsonapi.FromResource; all conversion to and from resource objects is done by the Handler.
+//
+// Errors of type *jsonapi.Error or jsonapi.Errors are sent to the client as is, and errors matching ErrNotFound or
+// ErrConflict (see errors.Is) result in a 404 Not Found or 409 Conflict error. Any other error results in a
+// 500 Internal Server Error, without any details.
+//
+// Resources included in compound documents are loaded in batches, one for each resource type and level of the
+// include paths, see jsonapi.IncludeResolver. A repository may implement the following interface to load a batch
+// with a single lookup, otherwise FindOne is called for each ID:
+//
+//	type batchRepository interface {
+//		FindMany(ctx context.Context, ids []string) ([]interface{}, error)
+//	}
+//
+// FindMany returns the values with the given IDs, in any order, leaving out those which do not exist.
+type Repository interface {
+	// New returns a new, empty value for a resource to be created.
+	New() interface{}
+
+	// FindAll returns the values matching the filters of the query, in the order and page it asks for.
+	FindAll(ctx context.Context, q *Query) ([]interface{}, error)
+
+	// FindOne returns the value with the given ID, or ErrNotFound.
+	FindOne(ctx context.Context, id string) (interface{}, error)
+
+	// FindRelated returns the values related to the value with the given ID by the relationship name, or
+	// ErrNotFound if there is no such value. For a to-one relationship, it returns at most one value.
+	FindRelated(ctx context.Context, id, name string) ([]interface{}, error)
+
+	// Create stores the new value v, populated from the request document, and assigns its ID unless the
+	// client generated it.
+	Create(ctx context.Context, v interface{}) error
+
+	// Update stores the value v, as returned by FindOne and updated from the request document.
+	Update(ctx context.Context, v interface{}) error
+
+	// Delete removes the value with the given ID, or returns ErrNotFound.
+	Delete(ctx context.Context, id string) error
+}
+
+type batchRepository interface {
+	FindMany(ctx context.Context, ids []string) ([]interface{}, error)
+}
+
+// errors
+
+var (
+	// ErrNotFound is returned by a Repository if a resource does not exist.
+	ErrNotFound = fmt.Errorf("%s: resource not found", packageName)
+
+	// ErrConflict is returned by a Repository if a resource cannot be stored because it conflicts with another
+	// resource, e.g. if a client-generated ID is already taken.
+	ErrConflict = fmt.Errorf("%s: resource conflicts with an existing resource", packageName)
+)
+
+const packageName string = "github.com/smotes/jsonapi/server"
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/smotes/jsonapi"
+)
+
+// writeDocument writes the document doc as the response with the given status, using the media type mt.
+func writeDocument(w http.ResponseWriter, mt jsonapi.MediaTypeParams, status int, doc *jsonapi.Document) {
+	if len(mt.Ext) > 0 || len(mt.Profile) > 0 {
+		doc.Info = mt.Info(version)
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		writeError(w, mt, err)
+		return
+	}
+	w.Header().Set("Content-Type", mt.String())
+	w.WriteHeader(status)
+	w.Write(b)
+}
+
+// writeError writes the errors document for err as the response, see toErrors.
+func writeError(w http.ResponseWriter, mt jsonapi.MediaTypeParams, err error) {
+	var mna *jsonapi.MethodNotAllowedError
+	if errors.As(err, &mna) {
+		mna.SetHeader(w.Header())
+	}
+
+	errs := toErrors(err)
+	b, err := json.Marshal(jsonapi.Document{Errors: errs})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", mt.String())
+	w.WriteHeader(statusOf(errs))
+	w.Write(b)
+}
+
+// toErrors converts err into the JSON API errors sent to the client.
+func toErrors(err error) jsonapi.Errors {
+	var (
+		es jsonapi.Errors
+		e  *jsonapi.Error
+	)
+	switch {
+	case errors.As(err, &es):
+		return es
+	case errors.As(err, &e):
+		return jsonapi.Errors{*e}
+	case errors.Is(err, ErrNotFound):
+		return jsonapi.Errors{statusError(http.StatusNotFound, "resource does not exist")}
+	case errors.Is(err, ErrConflict):
+		return jsonapi.Errors{statusError(http.StatusConflict, "resource conflicts with an existing resource")}
+	default:
+		return jsonapi.Errors{statusError(http.StatusInternalServerError, "")}
+	}
+}
+
+// statusOf returns the HTTP status for the errors: their status if they all share it, the most generally
+// applicable status of their class, i.e. 400 Bad Request or 500 Internal Server Error, otherwise.
+func statusOf(errs jsonapi.Errors) int {
+	status := 0
+	for _, e := range errs {
+		s, err := strconv.Atoi(e.Status)
+		if err != nil {
+			s = http.StatusInternalServerError
+		}
+		switch {
+		case status == 0 || status == s:
+			status = s
+		case status < 500 && s < 500:
+			status = http.StatusBadRequest
+		default:
+			status = http.StatusInternalServerError
+		}
+	}
+	if status == 0 {
+		return http.StatusInternalServerError
+	}
+	return status
+}
+
+func statusError(status int, detail string) jsonapi.Error {
+	return jsonapi.Error{
+		Status: fmt.Sprint(status),
+		Title:  http.StatusText(status),
+		Detail: detail,
+	}
+}
+
+// badRequest converts an error returned when decoding a request document or converting a resource sent by the
+// client into a 400 Bad Request error, unless it is a JSON API error already.
+func badRequest(title string, err error) error {
+	var (
+		es jsonapi.Errors
+		e  *jsonapi.Error
+	)
+	if errors.As(err, &es) || errors.As(err, &e) {
+		return err
+	}
+	e = &jsonapi.Error{
+		Status: fmt.Sprint(http.StatusBadRequest),
+		Title:  title,
+		Detail: err.Error(),
+	}
+	return e
+}
@@ -0,0 +1,466 @@
+// Package server implements an http.Handler serving JSON API resource collections, along with their relationship
+// and related resource endpoints, from Repository implementations.
+//
+// Consider the article type from the documentation of the jsonapi package, along with a repository storing
+// articles, which both would be implemented by the user of this package:
+//
+//	h := &server.Handler{BasePath: "/api"}
+//	h.Register("articles", articleRepository)
+//	h.Register("people", personRepository)
+//	http.Handle("/api/", h)
+//
+// The handler then serves the following endpoints for each registered type:
+//
+//	GET    /api/articles                        list articles
+//	POST   /api/articles                        create an article
+//	GET    /api/articles/1                      fetch an article
+//	PATCH  /api/articles/1                      update an article
+//	DELETE /api/articles/1                      delete an article
+//	GET    /api/articles/1/author               fetch the related author
+//	GET    /api/articles/1/relationships/author fetch the author relationship
+//	PATCH  /api/articles/1/relationships/tags   replace the tags relationship
+//	POST   /api/articles/1/relationships/tags   add to the tags relationship
+//	DELETE /api/articles/1/relationships/tags   remove from the tags relationship
+//
+// All conversion between resource objects and the custom types is done with jsonapi.ToResource and
+// jsonapi.FromResource, and relationships are updated with jsonapi.UpdateRelationship, so the custom types must
+// implement the respective adapter interfaces. The handler takes care of content negotiation, query parameters,
+// status codes and errors documents.
+package server // import "github.com/smotes/jsonapi/server"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/smotes/jsonapi"
+)
+
+// version is the version of the JSON API specification implemented by the handler.
+const version = "1.1"
+
+// Handler serves the resource collections of its registered types. Its zero value is ready to use, and it is
+// safe for concurrent use once all types are registered.
+type Handler struct {
+	// BasePath is the path the handler is mounted at, e.g. "/api". It is stripped from request paths, and
+	// prepended to the paths of links and Location headers.
+	BasePath string
+
+	// MediaTypes holds the supported extensions and profiles. If nil, none are supported.
+	MediaTypes *jsonapi.MediaTypeRegistry
+
+	// Relationships, if set, declares the relationships clients may include in compound documents, and limits
+	// the depth of include paths and the number of included resources. Relationships which are not declared are
+	// not served at relationship and related resource endpoints either. If nil, any relationship a resource has
+	// may be included.
+	Relationships *jsonapi.RelationshipGraph
+
+	mu    sync.RWMutex
+	types map[string]*collection
+}
+
+// collection is a registered resource type.
+type collection struct {
+	repo Repository
+	opts []jsonapi.FromResourceOption
+}
+
+// Register registers the repository repo to serve the resource collection of type typ. The options opts are
+// passed to jsonapi.FromResource when creating or updating resources, e.g. to set a client ID policy.
+//
+// It returns an error if typ is already registered.
+func (h *Handler) Register(typ string, repo Repository, opts ...jsonapi.FromResourceOption) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.types[typ]; ok {
+		return fmt.Errorf("%s: type %q is already registered", packageName, typ)
+	}
+	if h.types == nil {
+		h.types = make(map[string]*collection)
+	}
+	h.types[typ] = &collection{repo: repo, opts: opts}
+	return nil
+}
+
+// ServeHTTP dispatches the request to the endpoint its path and method correspond to.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mediaTypes := h.MediaTypes
+	if mediaTypes == nil {
+		mediaTypes = &jsonapi.MediaTypeRegistry{}
+	}
+
+	reqType, resType, err := mediaTypes.Negotiate(r)
+	if err != nil {
+		writeError(w, resType, err)
+		return
+	}
+
+	req := &request{
+		h:          h,
+		w:          w,
+		r:          r,
+		ctx:        r.Context(),
+		mt:         resType,
+		namespaces: mediaTypes.Namespaces(reqType.Ext),
+	}
+	if err := req.serve(); err != nil {
+		writeError(w, resType, err)
+	}
+}
+
+// paths returns the parser for the request paths of the endpoints served by the handler.
+func (h *Handler) paths() *jsonapi.PathParser {
+	return &jsonapi.PathParser{
+		BasePath: h.BasePath,
+		IsType: func(typ string) bool {
+			_, ok := h.collection(typ)
+			return ok
+		},
+	}
+}
+
+func (h *Handler) collection(typ string) (*collection, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	c, ok := h.types[typ]
+	return c, ok
+}
+
+func (h *Handler) repository(typ string) (Repository, bool) {
+	c, ok := h.collection(typ)
+	if !ok {
+		return nil, false
+	}
+	return c.repo, true
+}
+
+// request holds the state of a single request while it is served.
+type request struct {
+	h   *Handler
+	w   http.ResponseWriter
+	r   *http.Request
+	ctx context.Context
+
+	// mt is the media type of the response, and namespaces those of the extensions applied to the request
+	mt         jsonapi.MediaTypeParams
+	namespaces []string
+
+	// typ, id and rel are taken from the path of the request, and c is the collection of its type
+	target       jsonapi.Target
+	typ, id, rel string
+	c            *collection
+	q            *Query
+	inc          *jsonapi.IncludeResolver
+}
+
+func (req *request) serve() error {
+	target, err := req.h.paths().ParseRequest(req.r)
+	if err != nil {
+		return err
+	}
+	req.target = target
+	req.typ, req.id, req.rel = target.Type, target.ID, target.Relationship
+	req.c, _ = req.h.collection(target.Type)
+
+	q, err := ParseQuery(req.r.URL.Query())
+	if err != nil {
+		return err
+	}
+	req.q = q
+
+	if req.h.Relationships != nil && (target.Kind == jsonapi.CollectionTarget || target.Kind == jsonapi.ResourceTarget) {
+		if err := req.h.Relationships.ValidateInclude(target.Type, q.Include); err != nil {
+			return err
+		}
+	}
+
+	switch target.Kind {
+	case jsonapi.RelationshipTarget:
+		if req.r.Method == http.MethodGet {
+			return req.getRelationship()
+		}
+		return req.updateRelationship()
+	case jsonapi.RelatedTarget:
+		return req.getRelated()
+	case jsonapi.ResourceTarget:
+		switch req.r.Method {
+		case http.MethodPatch:
+			return req.updateResource()
+		case http.MethodDelete:
+			return req.deleteResource()
+		}
+		return req.getResource()
+	default:
+		if req.r.Method == http.MethodPost {
+			return req.createResource()
+		}
+		return req.getCollection()
+	}
+}
+
+func (req *request) getCollection() error {
+	vs, err := req.c.repo.FindAll(req.ctx, req.q)
+	if err != nil {
+		return err
+	}
+	doc, err := req.document(vs, true)
+	if err != nil {
+		return err
+	}
+	writeDocument(req.w, req.mt, http.StatusOK, doc)
+	return nil
+}
+
+func (req *request) getResource() error {
+	v, err := req.c.repo.FindOne(req.ctx, req.id)
+	if err != nil {
+		return err
+	}
+	doc, err := req.document([]interface{}{v}, false)
+	if err != nil {
+		return err
+	}
+	writeDocument(req.w, req.mt, http.StatusOK, doc)
+	return nil
+}
+
+func (req *request) createResource() error {
+	r, err := req.decodeResource()
+	if err != nil {
+		return err
+	}
+
+	v := req.c.repo.New()
+	opts := append([]jsonapi.FromResourceOption{jsonapi.ForCreate(), jsonapi.ExpectType(req.typ)}, req.c.opts...)
+	if err := jsonapi.FromResource(v, r, true, opts...); err != nil {
+		return badRequest("Invalid resource", err)
+	}
+	if err := req.c.repo.Create(req.ctx, v); err != nil {
+		return err
+	}
+
+	doc, err := req.document([]interface{}{v}, false)
+	if err != nil {
+		return err
+	}
+	// the document describes the created resource, so it links to the resource rather than to the collection
+	var created jsonapi.Resource
+	if err := json.Unmarshal(doc.Data, &created); err == nil && len(created.ID) > 0 {
+		location := req.path(jsonapi.Target{Kind: jsonapi.ResourceTarget, Type: created.Type, ID: created.ID})
+		req.w.Header().Set("Location", location)
+		doc.Links.AddString("self", location)
+	}
+	writeDocument(req.w, req.mt, http.StatusCreated, doc)
+	return nil
+}
+
+func (req *request) updateResource() error {
+	r, err := req.decodeResource()
+	if err != nil {
+		return err
+	}
+
+	v, err := req.c.repo.FindOne(req.ctx, req.id)
+	if err != nil {
+		return err
+	}
+	opts := append([]jsonapi.FromResourceOption{
+		jsonapi.ForUpdate(),
+		jsonapi.ExpectType(req.typ),
+		jsonapi.ExpectID(req.id),
+	}, req.c.opts...)
+	if err := jsonapi.FromResource(v, r, true, opts...); err != nil {
+		return badRequest("Invalid resource", err)
+	}
+	if err := req.c.repo.Update(req.ctx, v); err != nil {
+		return err
+	}
+
+	doc, err := req.document([]interface{}{v}, false)
+	if err != nil {
+		return err
+	}
+	writeDocument(req.w, req.mt, http.StatusOK, doc)
+	return nil
+}
+
+func (req *request) deleteResource() error {
+	if err := req.c.repo.Delete(req.ctx, req.id); err != nil {
+		return err
+	}
+	req.w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (req *request) getRelated() error {
+	_, rel, err := req.findRelationship()
+	if err != nil {
+		return err
+	}
+	vs, err := req.c.repo.FindRelated(req.ctx, req.id, req.rel)
+	if err != nil {
+		return err
+	}
+	doc, err := req.document(vs, rel.IsToMany() || len(vs) > 1)
+	if err != nil {
+		return err
+	}
+	writeDocument(req.w, req.mt, http.StatusOK, doc)
+	return nil
+}
+
+func (req *request) getRelationship() error {
+	_, rel, err := req.findRelationship()
+	if err != nil {
+		return err
+	}
+
+	links := jsonapi.Links{}
+	for key, v := range rel.Links {
+		links[key] = v
+	}
+	links.AddString("self", req.r.URL.Path)
+	related := req.target
+	related.Kind = jsonapi.RelatedTarget
+	links.AddString("related", req.path(related))
+
+	// a relationship without data or meta is empty as far as the handler knows
+	data := rel.Data
+	if data == nil && rel.Meta == nil {
+		data = []byte("null")
+	}
+	doc := &jsonapi.Document{Data: data, Links: links, Meta: rel.Meta}
+	writeDocument(req.w, req.mt, http.StatusOK, doc)
+	return nil
+}
+
+func (req *request) updateRelationship() error {
+	op, _ := jsonapi.RelationshipOpForMethod(req.r.Method)
+	data, err := jsonapi.DecodeRelationshipRequest(req.r.Body, op, req.namespaces...)
+	if err != nil {
+		return err
+	}
+
+	v, rel, err := req.findRelationship()
+	if err != nil {
+		return err
+	}
+	if op != jsonapi.RelationshipReplace && rel.Data != nil && !rel.IsToMany() {
+		e := statusError(http.StatusForbidden, fmt.Sprintf("relationship %q is not a to-many relationship", req.rel))
+		return &e
+	}
+	if err := jsonapi.UpdateRelationship(v, req.rel, op, data); err != nil {
+		return err
+	}
+	if err := req.c.repo.Update(req.ctx, v); err != nil {
+		return err
+	}
+	req.w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// findRelationship returns the request's resource and its relationship, or a 404 Not Found error if either does
+// not exist. A relationship exists if the resource object holds it, or if Relationships is set and declares it for
+// the resource type; in the latter case, a relationship the resource object does not hold, e.g. an empty one left
+// out by the custom type, is returned without data, leaving updates to the adapter implementation of the custom
+// type. If Relationships is set, relationships it does not declare do not exist.
+func (req *request) findRelationship() (interface{}, *jsonapi.Relationship, error) {
+	v, err := req.c.repo.FindOne(req.ctx, req.id)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := jsonapi.ToResource(v, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rel, held := r.Relationships.Get(req.rel)
+	declared := held
+	if g := req.h.Relationships; g != nil {
+		_, declared = g.Targets(req.typ, req.rel)
+	}
+	if !declared {
+		e := statusError(http.StatusNotFound, fmt.Sprintf("relationship %q does not exist", req.rel))
+		return nil, nil, &e
+	}
+	if rel == nil {
+		rel = &jsonapi.Relationship{}
+	}
+	return v, rel, nil
+}
+
+// decodeResource decodes the resource object sent as the primary data of the request document, in strict mode.
+// Numbers are kept as json.Number, so large integers reach the custom types without losing precision.
+func (req *request) decodeResource() (*jsonapi.Resource, error) {
+	doc := jsonapi.Document{}
+	dec := jsonapi.NewDecoder(req.r.Body)
+	dec.UseNumber()
+	dec.Strict()
+	dec.DisallowUnknownMembers(req.namespaces...)
+	if err := dec.Decode(&doc); err != nil {
+		return nil, badRequest("Invalid document", err)
+	}
+
+	// the primary data was validated along with the document, so it only needs to be decoded
+	r := &jsonapi.Resource{}
+	dec = jsonapi.NewDecoder(bytes.NewReader(doc.Data))
+	dec.UseNumber()
+	if err := dec.Decode(r); err != nil || len(r.Type) == 0 {
+		e := statusError(http.StatusBadRequest, "primary data must be a single resource object")
+		e.Source = &jsonapi.ErrorSource{Pointer: "/data"}
+		return nil, &e
+	}
+	return r, nil
+}
+
+// document returns the response document holding the values vs as primary data, either as an array if many is
+// true, or as a single resource or null, along with the included resources the query asks for.
+func (req *request) document(vs []interface{}, many bool) (*jsonapi.Document, error) {
+	rs := make([]jsonapi.Resource, len(vs))
+	data := make([]jsonapi.Resource, len(vs))
+	for i, v := range vs {
+		r, err := jsonapi.ToResource(v, true)
+		if err != nil {
+			return nil, err
+		}
+		rs[i] = *r
+		data[i] = req.q.sparse(r)
+	}
+
+	included, err := req.includer().Resolve(req.ctx, rs, req.q.Include)
+	if err != nil {
+		return nil, err
+	}
+	for i := range included {
+		included[i] = req.q.sparse(&included[i])
+	}
+
+	var b []byte
+	switch {
+	case many:
+		b, err = json.Marshal(data)
+	case len(data) > 0:
+		b, err = json.Marshal(data[0])
+	default:
+		b = []byte("null")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsonapi.Document{
+		Data:     b,
+		Included: included,
+		Links:    jsonapi.Links{"self": req.r.URL.RequestURI()},
+	}, nil
+}
+
+// path returns the path of the target t.
+func (req *request) path(t jsonapi.Target) string {
+	return req.h.paths().Path(t)
+}
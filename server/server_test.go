@@ -0,0 +1,271 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/smotes/jsonapi"
+)
+
+func serve(h http.Handler, method, target, body string) (*httptest.ResponseRecorder, *jsonapi.Document) {
+	var r *http.Request
+	if len(body) > 0 {
+		r = httptest.NewRequest(method, target, strings.NewReader(body))
+		r.Header.Set("Content-Type", jsonapi.MediaType)
+	} else {
+		r = httptest.NewRequest(method, target, nil)
+	}
+	r.Header.Set("Accept", jsonapi.MediaType)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	doc := &jsonapi.Document{}
+	if w.Body.Len() > 0 {
+		json.Unmarshal(w.Body.Bytes(), doc)
+	}
+	return w, doc
+}
+
+func TestHandler_ServeHTTP_Status(t *testing.T) {
+	tests := []struct {
+		method, target, body string
+		status               int
+	}{
+		{http.MethodGet, "/api/articles", "", http.StatusOK},
+		{http.MethodGet, "/api/articles/1", "", http.StatusOK},
+		{http.MethodGet, "/api/articles/3", "", http.StatusNotFound},
+		{http.MethodGet, "/api/comments", "", http.StatusNotFound},
+		{http.MethodGet, "/api/articles/1/author/extra/path", "", http.StatusNotFound},
+		{http.MethodGet, "/api/articles/1/relationships/comments", "", http.StatusNotFound},
+		{http.MethodGet, "/api/articles/1/comments", "", http.StatusNotFound},
+		{http.MethodPut, "/api/articles/1", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/articles?include=comments", "", http.StatusBadRequest},
+		{http.MethodGet, "/api/articles?foo=bar", "", http.StatusBadRequest},
+		{http.MethodGet, "/api/articles?camelCase=bar", "", http.StatusOK},
+		{http.MethodPost, "/api/articles", `{"data": {"type": "articles", "id": "7"}}`, http.StatusForbidden},
+		{http.MethodPost, "/api/articles", `{"data": {"type": "people"}}`, http.StatusConflict},
+		{http.MethodPost, "/api/articles", `{"data": {"type": "articles", "attribute": {}}}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/articles", `{"data": []}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/people", `{"data": {"type": "people", "id": "9"}}`, http.StatusConflict},
+		{http.MethodPatch, "/api/articles/1", `{"data": {"type": "articles", "id": "2"}}`, http.StatusConflict},
+		{http.MethodPatch, "/api/articles/3", `{"data": {"type": "articles", "id": "3"}}`, http.StatusNotFound},
+		{http.MethodDelete, "/api/articles/2", "", http.StatusNoContent},
+		{http.MethodDelete, "/api/articles/3", "", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		w, doc := serve(newTestHandler(), test.method, test.target, test.body)
+		if w.Code != test.status {
+			t.Errorf("%s %s: unexpected status, expected: %d, actual: %d: %s",
+				test.method, test.target, test.status, w.Code, w.Body)
+		}
+		if w.Code >= 400 && len(doc.Errors) == 0 {
+			t.Errorf("%s %s: expected errors document, actual: %s", test.method, test.target, w.Body)
+		}
+		if ct := w.Header().Get("Content-Type"); w.Code != http.StatusNoContent && ct != jsonapi.MediaType {
+			t.Errorf("%s %s: unexpected Content-Type: %s", test.method, test.target, ct)
+		}
+	}
+}
+
+func TestHandler_ServeHTTP_MethodNotAllowed(t *testing.T) {
+	w, _ := serve(newTestHandler(), http.MethodPost, "/api/articles/1", "")
+	if allow := w.Header().Get("Allow"); allow != "GET, PATCH, DELETE" {
+		t.Errorf("unexpected Allow header: %q", allow)
+	}
+}
+
+func TestHandler_ServeHTTP_Negotiation(t *testing.T) {
+	h := newTestHandler()
+
+	r := httptest.NewRequest(http.MethodPost, "/api/articles", strings.NewReader(`{"data": {"type": "articles"}}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415 for unsupported Content-Type, actual: %d", w.Code)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/api/articles", nil)
+	r.Header.Set("Accept", jsonapi.MediaType+`;ext="http://example.com/ext"`)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("expected 406 for unsupported extension in Accept, actual: %d", w.Code)
+	}
+}
+
+func TestHandler_ServeHTTP_Create(t *testing.T) {
+	h := newTestHandler()
+	w, doc := serve(h, http.MethodPost, "/api/articles", `{
+		"data": {
+			"type": "articles",
+			"attributes": {"title": "qux"},
+			"relationships": {"author": {"data": {"type": "people", "id": "9"}}}
+		}
+	}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("unexpected status, expected: %d, actual: %d: %s", http.StatusCreated, w.Code, w.Body)
+	}
+	if loc := w.Header().Get("Location"); loc != "/api/articles/3" {
+		t.Errorf("unexpected Location header: %q", loc)
+	}
+	if self, _ := doc.Links.GetString("self"); self != "/api/articles/3" {
+		t.Errorf("expected self link of created resource to match Location header, actual: %q", self)
+	}
+
+	r := jsonapi.Resource{}
+	if err := json.Unmarshal(doc.Data, &r); err != nil || r.ID != "3" {
+		t.Fatalf("unexpected primary data of created resource: %s", doc.Data)
+	}
+	if title, _ := r.Attributes.String("title"); title != "qux" {
+		t.Errorf("unexpected title of created resource: %q", title)
+	}
+
+	w, doc = serve(h, http.MethodGet, "/api/people/9/articles", "")
+	rs := []jsonapi.Resource{}
+	if err := json.Unmarshal(doc.Data, &rs); err != nil || len(rs) != 2 {
+		t.Errorf("expected created resource to be related to author: %s", w.Body)
+	}
+}
+
+func TestHandler_ServeHTTP_Update(t *testing.T) {
+	h := newTestHandler()
+	w, doc := serve(h, http.MethodPatch, "/api/articles/1", `{
+		"data": {"type": "articles", "id": "1", "relationships": {"author": {"data": null}}}
+	}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status, expected: %d, actual: %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+
+	r := jsonapi.Resource{}
+	json.Unmarshal(doc.Data, &r)
+	if title, _ := r.Attributes.String("title"); title != "foo" {
+		t.Errorf("expected attributes missing from request to be kept, actual: %q", title)
+	}
+	if rel, _ := r.Relationships.Get("author"); rel == nil || string(rel.Data) != "null" {
+		t.Errorf("expected relationship to be cleared, actual: %+v", rel)
+	}
+}
+
+func TestHandler_ServeHTTP_Include(t *testing.T) {
+	w, doc := serve(newTestHandler(), http.MethodGet, "/api/articles?include=author.articles&fields[people]=name", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status, expected: %d, actual: %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+
+	// article 1 is primary data already, so only its author is included
+	if len(doc.Included) != 1 || doc.Included[0].Type != "people" {
+		t.Fatalf("unexpected included resources: %+v", doc.Included)
+	}
+	if p := doc.Included[0]; p.Relationships != nil || !p.HasAttribute("name") {
+		t.Errorf("expected sparse fieldset to apply to included resource: %+v", p)
+	}
+	if self, _ := doc.Links.GetString("self"); self != "/api/articles?include=author.articles&fields[people]=name" {
+		t.Errorf("unexpected self link: %q", self)
+	}
+}
+
+func TestHandler_ServeHTTP_Related(t *testing.T) {
+	h := newTestHandler()
+
+	_, doc := serve(h, http.MethodGet, "/api/articles/1/author", "")
+	r := jsonapi.Resource{}
+	if err := json.Unmarshal(doc.Data, &r); err != nil || r.ID != "9" {
+		t.Errorf("unexpected related resource: %s", doc.Data)
+	}
+
+	_, doc = serve(h, http.MethodGet, "/api/articles/2/author", "")
+	if string(doc.Data) != "null" {
+		t.Errorf("expected null for empty to-one relationship, actual: %s", doc.Data)
+	}
+
+	_, doc = serve(h, http.MethodGet, "/api/articles/1/relationships/author", "")
+	if string(doc.Data) != `{"type":"people","id":"9"}` {
+		t.Errorf("unexpected relationship data: %s", doc.Data)
+	}
+	if related, _ := doc.Links.GetString("related"); related != "/api/articles/1/author" {
+		t.Errorf("unexpected related link: %q", related)
+	}
+}
+
+func TestHandler_ServeHTTP_UpdateRelationship(t *testing.T) {
+	h := newTestHandler()
+
+	tests := []struct {
+		method, target, body string
+		status               int
+		tags                 string
+	}{
+		{http.MethodPost, "/api/articles/1/relationships/tags",
+			`{"data": [{"type": "tags", "id": "a"}, {"type": "tags", "id": "b"}]}`, http.StatusNoContent,
+			`[{"type":"tags","id":"a"},{"type":"tags","id":"b"}]`},
+		{http.MethodDelete, "/api/articles/1/relationships/tags",
+			`{"data": [{"type": "tags", "id": "a"}, {"type": "tags", "id": "c"}]}`, http.StatusNoContent,
+			`[{"type":"tags","id":"b"}]`},
+		{http.MethodPatch, "/api/articles/1/relationships/tags",
+			`{"data": []}`, http.StatusForbidden, `[{"type":"tags","id":"b"}]`},
+		{http.MethodPost, "/api/articles/1/relationships/tags",
+			`{"data": {"type": "tags", "id": "c"}}`, http.StatusBadRequest, `[{"type":"tags","id":"b"}]`},
+		{http.MethodPost, "/api/articles/1/relationships/tags",
+			`{"data": [{"id": "c"}]}`, http.StatusBadRequest, `[{"type":"tags","id":"b"}]`},
+		{http.MethodPost, "/api/articles/1/relationships/author",
+			`{"data": [{"type": "people", "id": "9"}]}`, http.StatusForbidden, `[{"type":"tags","id":"b"}]`},
+		{http.MethodPost, "/api/articles/1/relationships/comments",
+			`{"data": []}`, http.StatusNotFound, `[{"type":"tags","id":"b"}]`},
+	}
+
+	for _, test := range tests {
+		w, _ := serve(h, test.method, test.target, test.body)
+		if w.Code != test.status {
+			t.Errorf("%s %s %s: unexpected status, expected: %d, actual: %d: %s",
+				test.method, test.target, test.body, test.status, w.Code, w.Body)
+		}
+
+		_, doc := serve(h, http.MethodGet, "/api/articles/1/relationships/tags", "")
+		if string(doc.Data) != test.tags {
+			t.Errorf("%s %s %s: unexpected relationship data, expected: %s, actual: %s",
+				test.method, test.target, test.body, test.tags, doc.Data)
+		}
+	}
+}
+
+func TestHandler_ServeHTTP_Relationships(t *testing.T) {
+	h := newTestHandler()
+	h.Relationships = &jsonapi.RelationshipGraph{MaxDepth: 1, MaxIncluded: 1}
+	h.Relationships.Add("articles", "author", "people")
+	h.Relationships.Add("articles", "tags", "tags")
+	h.Relationships.Add("people", "articles", "articles")
+
+	tests := []struct {
+		target string
+		status int
+	}{
+		{"/api/articles?include=author", http.StatusOK},
+		{"/api/articles/1?include=tags", http.StatusOK},
+		{"/api/articles?include=comments", http.StatusBadRequest},
+		{"/api/articles?include=author.articles", http.StatusBadRequest},
+		{"/api/articles/1/relationships/tags", http.StatusOK},
+		{"/api/articles/1/relationships/comments", http.StatusNotFound},
+		{"/api/articles/1/comments", http.StatusNotFound},
+	}
+	for _, test := range tests {
+		w, doc := serve(h, http.MethodGet, test.target, "")
+		if w.Code != test.status {
+			t.Errorf("%s: unexpected status, expected: %d, actual: %d: %s", test.target, test.status, w.Code, w.Body)
+		}
+		if w.Code == http.StatusBadRequest && doc.Errors[0].Source.Parameter != "include" {
+			t.Errorf("%s: expected include parameter as error source: %s", test.target, w.Body)
+		}
+	}
+
+	h.Relationships.MaxIncluded = 0
+	_, doc := serve(h, http.MethodGet, "/api/articles?include=author", "")
+	if len(doc.Included) != 1 {
+		t.Errorf("expected author to be included, actual: %+v", doc.Included)
+	}
+}
@@ -150,3 +150,41 @@ func TestToResource_WhenGetMetaError(t *testing.T) {
 		t.Error("ToResource should return nil/error from adapter.GetMeta when it returns an error")
 	}
 }
+
+// attribute access
+
+type accessReadAdapter struct{}
+
+func (a *accessReadAdapter) GetID() (string, error) {
+	return "1", nil
+}
+
+func (a *accessReadAdapter) GetType() (string, error) {
+	return "users", nil
+}
+
+func (a *accessReadAdapter) GetAttributes() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"name":     "foo",
+		"password": "secret",
+	}, nil
+}
+
+func (a *accessReadAdapter) GetAttributeAccess() map[string]jsonapi.AttributeAccess {
+	return map[string]jsonapi.AttributeAccess{
+		"password": jsonapi.WriteOnly,
+	}
+}
+
+func TestToResource_OmitsWriteOnlyAttributes(t *testing.T) {
+	r, err := jsonapi.ToResource(&accessReadAdapter{}, true)
+	if err != nil {
+		t.Fatalf("unexpected error from ToResource: %+v", err)
+	}
+	if r.HasAttribute("password") {
+		t.Error("ToResource should omit write-only attributes")
+	}
+	if !r.HasAttribute("name") {
+		t.Error("ToResource should keep attributes which are not write-only")
+	}
+}
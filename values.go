@@ -0,0 +1,172 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// conversion helpers shared by the typed getters on Meta and other free-form JSON objects
+
+func toString(v interface{}) (string, bool) {
+	s, ok := v.(string)
+	return s, ok
+}
+
+func toBool(v interface{}) (bool, bool) {
+	b, ok := v.(bool)
+	return b, ok
+}
+
+func toInt(v interface{}) (int, bool) {
+	i, ok := toInt64(v)
+	if !ok || int64(int(i)) != i {
+		return 0, false
+	}
+	return int(i), true
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch typ := v.(type) {
+	case int:
+		return int64(typ), true
+	case int8:
+		return int64(typ), true
+	case int16:
+		return int64(typ), true
+	case int32:
+		return int64(typ), true
+	case int64:
+		return typ, true
+	case uint:
+		return uintToInt64(uint64(typ))
+	case uint8:
+		return int64(typ), true
+	case uint16:
+		return int64(typ), true
+	case uint32:
+		return int64(typ), true
+	case uint64:
+		return uintToInt64(typ)
+	case float32:
+		return floatToInt64(float64(typ))
+	case float64:
+		return floatToInt64(typ)
+	case json.Number:
+		if i, err := typ.Int64(); err == nil {
+			return i, true
+		}
+		// allow integral numbers in exponent notation, e.g. 1e3
+		if f, err := typ.Float64(); err == nil {
+			return floatToInt64(f)
+		}
+	}
+	return 0, false
+}
+
+func toUint64(v interface{}) (uint64, bool) {
+	switch typ := v.(type) {
+	case uint:
+		return uint64(typ), true
+	case uint64:
+		return typ, true
+	case json.Number:
+		if u, err := strconv.ParseUint(string(typ), 10, 64); err == nil {
+			return u, true
+		}
+	}
+	if i, ok := toInt64(v); ok && i >= 0 {
+		return uint64(i), true
+	}
+	return 0, false
+}
+
+func toBigInt(v interface{}) (*big.Int, bool) {
+	switch typ := v.(type) {
+	case *big.Int:
+		return typ, true
+	case json.Number:
+		if i, ok := new(big.Int).SetString(string(typ), 10); ok {
+			return i, true
+		}
+		// allow integral numbers in exponent notation, e.g. 1e30
+		if r, ok := new(big.Rat).SetString(string(typ)); ok && r.IsInt() {
+			return r.Num(), true
+		}
+		return nil, false
+	}
+	if u, ok := toUint64(v); ok {
+		return new(big.Int).SetUint64(u), true
+	}
+	if i, ok := toInt64(v); ok {
+		return big.NewInt(i), true
+	}
+	return nil, false
+}
+
+func toBigRat(v interface{}) (*big.Rat, bool) {
+	switch typ := v.(type) {
+	case *big.Rat:
+		return typ, true
+	case json.Number:
+		return new(big.Rat).SetString(string(typ))
+	case float64:
+		r := new(big.Rat)
+		if math.IsInf(typ, 0) || math.IsNaN(typ) {
+			return nil, false
+		}
+		return r.SetFloat64(typ), true
+	case float32:
+		return toBigRat(float64(typ))
+	}
+	if i, ok := toBigInt(v); ok {
+		return new(big.Rat).SetInt(i), true
+	}
+	return nil, false
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch typ := v.(type) {
+	case float64:
+		return typ, true
+	case float32:
+		return float64(typ), true
+	case json.Number:
+		f, err := typ.Float64()
+		return f, err == nil
+	}
+	if i, ok := toInt64(v); ok {
+		return float64(i), true
+	}
+	return 0, false
+}
+
+func toTime(v interface{}) (time.Time, bool) {
+	switch typ := v.(type) {
+	case time.Time:
+		return typ, true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, typ)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+func uintToInt64(u uint64) (int64, bool) {
+	if u > math.MaxInt64 {
+		return 0, false
+	}
+	return int64(u), true
+}
+
+// floatToInt64 converts f to an int64 if it is integral and within the range of integers a float64 represents
+// exactly. Values at or beyond 2^53 are rejected, as they may already have been rounded when decoded.
+func floatToInt64(f float64) (int64, bool) {
+	const maxExact = 1 << 53
+	if f != math.Trunc(f) || f >= maxExact || f <= -maxExact {
+		return 0, false
+	}
+	return int64(f), true
+}